@@ -0,0 +1,147 @@
+package clock
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Commands run when a countdown expires or reaches a threshold
+ */
+
+// timerAction runs a command once when a countdown reaches its threshold
+type timerAction struct {
+	counter   int
+	threshold time.Duration // Time left when the command is run, 0 for expiry
+	command   string
+	armed     bool // Set while the countdown is above the threshold
+}
+
+// ValidateTimerAction checks the syntax of a timer action in the form of
+// "counter command" for expiry actions or "counter seconds command" for
+// threshold actions, eg. "1 /clock/timer/0/countup" or "1 60 /clock/flash".
+func ValidateTimerAction(spec string, counters int, threshold bool) error {
+	_, err := parseTimerAction(spec, counters, threshold)
+	return err
+}
+
+func parseTimerAction(spec string, counters int, threshold bool) (*timerAction, error) {
+	fields := 2
+	format := "counter and command"
+	if threshold {
+		fields = 3
+		format = "counter, seconds and command"
+	}
+	parts := strings.Fields(spec)
+	if len(parts) < fields {
+		return nil, fmt.Errorf("expected %s: %q", format, spec)
+	}
+
+	a := timerAction{}
+	var err error
+	a.counter, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid counter number: %s", parts[0])
+	} else if a.counter < 0 || a.counter >= counters {
+		return nil, fmt.Errorf("counter number %d out of range (have %d counters)", a.counter, counters)
+	}
+
+	if threshold {
+		seconds, err := strconv.Atoi(parts[1])
+		if err != nil || seconds < 1 {
+			return nil, fmt.Errorf("invalid threshold seconds: %s", parts[1])
+		}
+		a.threshold = time.Duration(seconds) * time.Second
+	}
+
+	// Keep the command as written to preserve quoted arguments
+	a.command = strings.TrimSpace(spec)
+	for _, f := range parts[:fields-1] {
+		a.command = strings.TrimSpace(strings.TrimPrefix(a.command, f))
+	}
+	if _, err := ParseCommand(a.command); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// loadTimerActions adds the timer actions from the configuration
+func (engine *Engine) loadTimerActions(specs []string, threshold bool) error {
+	for _, spec := range specs {
+		a, err := parseTimerAction(spec, len(engine.Counters), threshold)
+		if err == nil {
+			err = engine.addTimerAction(a)
+		}
+		if err != nil {
+			return fmt.Errorf("timer action %q: %v", spec, err)
+		}
+	}
+	return nil
+}
+
+// AddTimerAction adds a command to run when the counter expires or reaches the threshold
+func (engine *Engine) AddTimerAction(counter int, threshold time.Duration, command string) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.addTimerAction(&timerAction{counter: counter, threshold: threshold, command: command})
+}
+
+func (engine *Engine) addTimerAction(a *timerAction) error {
+	if a.counter < 0 || a.counter >= len(engine.Counters) {
+		return fmt.Errorf("counter number %d out of range (have %d counters)", a.counter, len(engine.Counters))
+	}
+	if a.threshold < 0 {
+		return fmt.Errorf("negative threshold: %v", a.threshold)
+	}
+	msg, err := ParseCommand(a.command)
+	if err != nil {
+		return err
+	}
+	if len(engine.commands.decode(msg)) == 0 {
+		return fmt.Errorf("unknown or invalid command: %s", a.command)
+	}
+
+	engine.actions = append(engine.actions, a)
+	if a.threshold == 0 {
+		log.Printf("Counter %d expire action: %s", a.counter, a.command)
+	} else {
+		log.Printf("Counter %d threshold action at %v: %s", a.counter, a.threshold, a.command)
+	}
+	return nil
+}
+
+// clearTimerActions removes all actions from a counter
+func (engine *Engine) clearTimerActions(counter int) {
+	actions := engine.actions[:0]
+	for _, a := range engine.actions {
+		if a.counter != counter {
+			actions = append(actions, a)
+		}
+	}
+	engine.actions = actions
+}
+
+// checkTimerActions runs the actions of countdowns that have crossed their thresholds
+func (engine *Engine) checkTimerActions(t time.Time) {
+	// Iterate over a copy, the commands can modify the actions
+	for _, a := range append([]*timerAction(nil), engine.actions...) {
+		counter := engine.Counters[a.counter]
+		if !counter.active || !counter.countdown || counter.media != nil || counter.slave != nil {
+			a.armed = false
+			continue
+		}
+
+		if counter.Diff(t) > a.threshold {
+			a.armed = true
+		} else if a.armed {
+			a.armed = false
+			log.Printf("Running counter %d action: %s", a.counter, a.command)
+			if err := engine.runCommand(a.command); err != nil {
+				log.Printf("Counter %d action: %v", a.counter, err)
+			}
+		}
+	}
+}
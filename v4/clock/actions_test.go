@@ -0,0 +1,118 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimerActionOncePerCrossing(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.ExpireActions = []string{"1 /clock/timer/2/countup"}
+		o.ThresholdActions = []string{"1 30 /clock/timer/3/countup"}
+	})
+	check := func() {
+		engine.mutex.Lock()
+		engine.checkTimerActions(fake.Now())
+		engine.mutex.Unlock()
+	}
+	active := func(counter int) bool {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
+		return engine.Counters[counter].active
+	}
+
+	engine.StartCounter(1, true, time.Minute)
+	check()
+	if active(2) || active(3) {
+		t.Fatalf("actions ran before the thresholds")
+	}
+
+	fake.Advance(31 * time.Second)
+	check()
+	if !active(3) {
+		t.Errorf("threshold action did not run at 29s left")
+	}
+	if active(2) {
+		t.Errorf("expire action ran before expiry")
+	}
+
+	fake.Advance(30 * time.Second)
+	check()
+	if !active(2) {
+		t.Fatalf("expire action did not run")
+	}
+
+	// Stopped targets must stay stopped while the countdown stays expired
+	engine.StopCounter(2)
+	engine.StopCounter(3)
+	for i := 0; i < 3; i++ {
+		fake.Advance(time.Second)
+		check()
+	}
+	if active(2) || active(3) {
+		t.Errorf("actions ran again without a new crossing")
+	}
+
+	// Restarting the countdown arms the actions for the next crossing
+	engine.StartCounter(1, true, time.Minute)
+	check()
+	fake.Advance(31 * time.Second)
+	check()
+	if !active(3) {
+		t.Errorf("threshold action did not run after the restart")
+	}
+	fake.Advance(30 * time.Second)
+	check()
+	if !active(2) {
+		t.Errorf("expire action did not run after the restart")
+	}
+}
+
+// TestTimerActionsWithoutOSC checks that the engine loop runs the actions with OSC disabled
+func TestTimerActionsWithoutOSC(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.ExpireActions = []string{"1 /clock/timer/2/countup"}
+	})
+	engine.StartCounter(1, true, time.Minute)
+	armed := func() bool {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
+		return engine.actions[0].armed
+	}
+	deadline := time.Now().Add(10 * checkTimer)
+	for !armed() && time.Now().Before(deadline) {
+		time.Sleep(checkTimer / 2)
+	}
+	fake.Advance(2 * time.Minute)
+
+	deadline = time.Now().Add(10 * checkTimer)
+	for time.Now().Before(deadline) {
+		engine.mutex.Lock()
+		active := engine.Counters[2].active
+		engine.mutex.Unlock()
+		if active {
+			return
+		}
+		time.Sleep(checkTimer / 2)
+	}
+	t.Errorf("expire action did not run with OSC disabled")
+}
+
+// TestTimerChain checks that an expire action can start the next countdown
+func TestTimerChain(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.ExpireActions = []string{"1 /clock/timer/2/countdown 120"}
+	})
+	engine.StartCounter(1, true, time.Minute)
+	engine.mutex.Lock()
+	engine.checkTimerActions(fake.Now())
+	engine.mutex.Unlock()
+
+	fake.Advance(time.Minute)
+	engine.mutex.Lock()
+	engine.checkTimerActions(fake.Now())
+	engine.mutex.Unlock()
+	if text := engine.State().Clocks[1].Text; text != "00:02:00" {
+		t.Errorf("chained countdown = %q, want 00:02:00", text)
+	}
+}
@@ -53,13 +53,17 @@ func (engine *Engine) updateMilluminClock(state millumin.State) error {
 
 		progress := float64(remaining) / float64(total)
 
+		engine.mutex.Lock()
 		engine.milluminCounter.SetMedia(hours, minutes, seconds, 0, remaining, progress, layerState.Paused, false)
+		engine.mutex.Unlock()
 		engine.sendMedia("millumin", hours, minutes, seconds, 0, int32(layerState.Remaining()+1), progress, layerState.Paused, false)
 
 		return nil
 	}
 	// No playing media found
+	engine.mutex.Lock()
 	engine.milluminCounter.ResetMedia()
+	engine.mutex.Unlock()
 	engine.sendResetMedia("millumin")
 
 	return err
@@ -90,7 +94,9 @@ func (engine *Engine) updateMittiClock(state mitti.State) error {
 	debug.Printf("Mitti update, remaining: %v total: %v\n", remaining.Seconds(), total.Seconds())
 
 	debug.Printf(" -> update state: %02d:%02d:%02d", state.Hours, state.Minutes, state.Seconds)
+	engine.mutex.Lock()
 	engine.mittiCounter.SetMedia(hours, minutes, seconds, frames, remaining, progress, state.Paused, state.Loop)
+	engine.mutex.Unlock()
 	engine.sendMedia("mitti", hours, minutes, seconds, frames, int32(state.Remaining), progress, state.Paused, state.Loop)
 
 	/* TODO: loop?
@@ -119,7 +125,9 @@ func (engine *Engine) runMittiClockClient(listenChan chan mitti.State) {
 				debug.Printf("Mitti: update clock: %v\n", state)
 			}
 		case <-timeout.C:
+			engine.mutex.Lock()
 			engine.mittiCounter.ResetMedia()
+			engine.mutex.Unlock()
 			engine.sendResetMedia("mitti")
 		}
 	}
@@ -134,6 +142,8 @@ func (engine *Engine) startClockClient(milluminListener *millumin.Listener, mitt
 
 func (engine *Engine) sendMedia(player string, hours, minutes, seconds, frames, remaining int32, progress float64, paused, looping bool) error {
 	if engine.oscDests == nil {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
 		switch player {
 		case "millumin":
 			engine.milluminCounter.SetMedia(hours, minutes, seconds, frames, time.Duration(remaining)*time.Second, progress, paused, looping)
@@ -157,6 +167,8 @@ func (engine *Engine) sendMedia(player string, hours, minutes, seconds, frames,
 
 func (engine *Engine) sendResetMedia(player string) error {
 	if engine.oscDests == nil {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
 		switch player {
 		case "millumin":
 			engine.milluminCounter.ResetMedia()
@@ -6,12 +6,14 @@ import (
 	"log"
 	"net"
 	"strings"
+	"sync"
 	"time"
 )
 
 const interfacePollTime = 5 * time.Second
 
 type feedbackDestination struct {
+	mutex    sync.Mutex // Protects udpConns against the monitor goroutine
 	udpConns []*net.UDPConn
 	address  string
 }
@@ -26,6 +28,8 @@ func initFeedback(address string) *feedbackDestination {
 
 func (fbDest *feedbackDestination) Write(data []byte) {
 	debug.Printf("Writing data to connections\n")
+	fbDest.mutex.Lock()
+	defer fbDest.mutex.Unlock()
 	for _, conn := range fbDest.udpConns {
 		if _, err := conn.Write(data); err != nil {
 			debug.Printf(" -> Error writing to udp connection %v", conn)
@@ -62,7 +66,9 @@ func (fbDest *feedbackDestination) singleAddr() {
 		debug.Printf("Feedback: sending to %v", fbDest.address)
 		udpConns = append(udpConns, udpConn)
 	}
+	fbDest.mutex.Lock()
 	fbDest.udpConns = udpConns
+	fbDest.mutex.Unlock()
 }
 
 func (fbDest *feedbackDestination) broadcastAll(port string) {
@@ -98,7 +104,9 @@ func (fbDest *feedbackDestination) broadcastAll(port string) {
 			}
 		}
 	}
+	fbDest.mutex.Lock()
 	fbDest.udpConns = udpConns
+	fbDest.mutex.Unlock()
 }
 
 func (fbDest *feedbackDestination) String() string {
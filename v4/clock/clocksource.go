@@ -2,6 +2,7 @@ package clock
 
 import (
 	// "github.com/stanchan/clock-8001/v3/debug"
+	"fmt"
 	"image/color"
 	"time"
 )
@@ -9,16 +10,169 @@ import (
 const ()
 
 type source struct {
-	counter *Counter       // timer
-	title   string         // title displayed on the screen if possible
-	tz      *time.Location // timezone to use
-
-	// Booleans controlling what might be displayed by this clock data source
-	ltc       bool // LTC timecode decoded from sound input
-	timer     bool // Countdown / up timer
-	tod       bool // Time of day, lowest priority
-	hidden    bool // Master control to turn output off
+	counter       *Counter       // timer
+	counterNumber int            // number of the timer counter
+	title         string         // title displayed on the screen if possible
+	tz            *time.Location // timezone to use
+
+	inputs    []sourceInput // Inputs in priority order, the first active one is displayed
+	inputSpec string        // Input list as configured
+	hidden    bool          // Master control to turn output off
 	textColor color.RGBA
 	bgColor   color.RGBA
+	cueColors []color.RGBA // Colors of the running rundown segment or preset, shown over textColor and bgColor, nil if none
 	overtime  color.RGBA
+
+	// Display settings, initialized from the global options if not set for the source
+	overtimeCountMode  string        // zero, blank or continue
+	overtimeVisibility string        // blink, background, both or none
+	format12h          bool          // Use 12 hour format for time-of-day
+	displaySeconds     bool          // Show seconds on time-of-day
+	subSeconds         time.Duration // Show tenths of seconds on timers under this, 0 disables
+	hundredths         bool          // Show hundredths instead of tenths with subSeconds
+	format             string        // Format string for the clock text, default if empty
+	overtimeFormat     string        // Format string for expired countdowns, format if empty
+	backTime           bool          // Show the time of day a running countdown ends
+
+	defaultTitle  string       // title from the configuration
+	defaultHidden bool         // hidden flag from the configuration
+	defaultColors []color.RGBA // text and background colors from the configuration, nil until set
+	colorOverride bool         // colors have been set over OSC
+}
+
+func validateOvertimeMode(mode string) error {
+	switch mode {
+	case "zero", "blank", "continue":
+		return nil
+	}
+	return fmt.Errorf("invalid overtime count mode: %q", mode)
+}
+
+func validateTimeFormat(format string) error {
+	switch format {
+	case "12h", "24h":
+		return nil
+	}
+	return fmt.Errorf("invalid time format: %q", format)
+}
+
+func validateOvertimeVisibility(visibility string) error {
+	switch visibility {
+	case "blink", "background", "both", "none":
+		return nil
+	}
+	return fmt.Errorf("invalid overtime visibility: %q", visibility)
+}
+
+// getSource returns the source with the given 0-based index
+func (engine *Engine) getSource(source int) (*source, error) {
+	if source < 0 || source >= len(engine.sources) {
+		return nil, fmt.Errorf("source number %d out of range (have %d sources)", source+1, len(engine.sources))
+	}
+	return engine.sources[source], nil
+}
+
+func (engine *Engine) setSourceOvertimeMode(source int, mode string) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	if err := validateOvertimeMode(mode); err != nil {
+		return err
+	}
+	s.overtimeCountMode = mode
+	return nil
+}
+
+func (engine *Engine) setSourceOvertimeVisibility(source int, visibility string) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	if err := validateOvertimeVisibility(visibility); err != nil {
+		return err
+	}
+	s.overtimeVisibility = visibility
+	return nil
+}
+
+func (engine *Engine) setSourceFormat(source int, format12h bool) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	s.format12h = format12h
+	return nil
+}
+
+func (engine *Engine) setSourceSeconds(source int, display bool) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	s.displaySeconds = display
+	return nil
+}
+
+func (engine *Engine) setSourceBackTime(source int, backTime bool) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	s.backTime = backTime
+	return nil
+}
+
+// setDisplaySeconds toggles the seconds display on all sources
+func (engine *Engine) setDisplaySeconds(display bool) {
+	engine.displaySeconds = display
+	for _, s := range engine.sources {
+		s.displaySeconds = display
+	}
+}
+
+func (engine *Engine) setSourceTimezone(source int, zone string) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	tz, err := time.LoadLocation(zone)
+	if err != nil {
+		return fmt.Errorf("unknown time zone: %s", zone)
+	}
+	s.tz = tz
+	// Schedule entries of the source run at their time of day in the new zone
+	for _, e := range engine.schedule {
+		if e.source == source+1 {
+			e.next = e.nextOccurrence(engine.timeSource.Now(), tz)
+		}
+	}
+	return nil
+}
+
+func (engine *Engine) setSourceCounter(source, counter int) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	if counter < 0 || counter >= len(engine.Counters) {
+		return fmt.Errorf("counter number %d out of range (have %d counters)", counter, len(engine.Counters))
+	}
+	s.counter = engine.Counters[counter]
+	s.counterNumber = counter
+	return nil
+}
+
+func (engine *Engine) setSourceInputs(source int, spec string) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	inputs, err := parseInputs(spec, len(engine.Counters))
+	if err != nil {
+		return err
+	}
+	s.inputs = inputs
+	s.inputSpec = spec
+	return nil
 }
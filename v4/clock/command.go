@@ -0,0 +1,110 @@
+package clock
+
+import (
+	"fmt"
+	"github.com/stanchan/go-osc/osc"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+ * Engine commands written as osc messages in text form, eg.
+ * /clock/timer/1/countdown/target "19:30:00"
+ */
+
+// ParseCommand parses a command in the form of a osc address followed by
+// whitespace separated arguments. Integer arguments are sent as int32,
+// decimal numbers as float32, true and false as booleans and everything
+// else as strings. Double quotes force a string argument and allow spaces.
+func ParseCommand(command string) (*osc.Message, error) {
+	fields, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	if fields[0].quoted || !strings.HasPrefix(fields[0].text, "/clock/") {
+		return nil, fmt.Errorf("command address must start with /clock/: %s", fields[0].text)
+	}
+
+	msg := osc.NewMessage(fields[0].text)
+	for _, f := range fields[1:] {
+		msg.Append(f.argument())
+	}
+	return msg, nil
+}
+
+type commandField struct {
+	text   string
+	quoted bool
+}
+
+func (f commandField) argument() interface{} {
+	if f.quoted {
+		return f.text
+	}
+	if i, err := strconv.ParseInt(f.text, 10, 32); err == nil {
+		return int32(i)
+	}
+	if strings.Contains(f.text, ".") {
+		if v, err := strconv.ParseFloat(f.text, 32); err == nil {
+			return float32(v)
+		}
+	}
+	switch f.text {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return f.text
+}
+
+// splitCommand splits the command on whitespace, keeping quoted strings intact
+func splitCommand(command string) ([]commandField, error) {
+	var fields []commandField
+	rest := strings.TrimSpace(command)
+
+	for rest != "" {
+		if rest[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("unterminated quoted string: %s", rest)
+			}
+			text, _ := strconv.Unquote(quoted)
+			fields = append(fields, commandField{text: text, quoted: true})
+			rest = rest[len(quoted):]
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			fields = append(fields, commandField{text: rest[:end]})
+			rest = rest[end:]
+		}
+		if rest != "" && !unicode.IsSpace(rune(rest[0])) {
+			return nil, fmt.Errorf("missing space after quoted string: %s", rest)
+		}
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+	}
+	return fields, nil
+}
+
+// runCommand decodes a command and applies it to the engine state.
+// The caller must hold engine.mutex.
+func (engine *Engine) runCommand(command string) error {
+	msg, err := ParseCommand(command)
+	if err != nil {
+		return err
+	}
+	messages := engine.commands.decode(msg)
+	if len(messages) == 0 {
+		return fmt.Errorf("unknown or invalid command: %s", command)
+	}
+	for _, m := range messages {
+		engine.handleMessage(m)
+	}
+	return nil
+}
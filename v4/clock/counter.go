@@ -16,8 +16,12 @@ const (
 	autoColorStart = iota
 	autoColorWarn  = iota
 	autoColorEnd   = iota
+	autoColorStep  = iota // First threshold profile step, two states per step for blinking
 )
 
+// maxRate limits the counting speed of rate adjusted counters
+const maxRate = 100
+
 // Counter abstracts a generic counter counting up or down
 type Counter struct {
 	state          *counterState
@@ -28,6 +32,10 @@ type Counter struct {
 	paused         bool // Is the counter paused?
 	signalColor    color.RGBA
 	autoColorState int
+	thresholds     thresholdProfile // Signal color steps, global thresholds if nil
+	laps           []time.Duration  // Elapsed time at each recorded lap of a count up
+	rate           float64          // Counting speed relative to real time, 0 is the same as 1
+	timeSource     TimeSource       // Source for the current time, system clock if nil
 }
 
 type slaveState struct {
@@ -63,6 +71,7 @@ type CounterOutput struct {
 	Paused      bool          // True if counter has been paused
 	Looping     bool          // True if the playing media is looping in the player
 	Expired     bool          // Has the countdown timer expired?
+	Days        int           // Whole days of the timer, included in Hours
 	Hours       int           // Hour part of the timer
 	Minutes     int           // Minutes of the timer, 0-60
 	Seconds     int           // Seconds of the timer, 0-60
@@ -71,8 +80,12 @@ type CounterOutput struct {
 	Compact     string        // Compact 4-character output
 	Progress    float64       // Percentage of total time elapsed of the countdown, 0-1
 	Diff        time.Duration // raw difference
+	Fraction    float64       // Fraction of the current second, 0-1
+	Rate        float64       // Counting speed relative to real time
 	HideHours   bool
 	SignalColor color.RGBA
+	Laps        []time.Duration // Durations of the completed laps of a stopwatch
+	LapTime     time.Duration   // Elapsed time on the current lap
 }
 
 // Output generates the static output of the counter for use in clock displays
@@ -86,6 +99,7 @@ func (counter *Counter) Output(t time.Time) *CounterOutput {
 		out = counter.normalOutput(t)
 	}
 	out.SignalColor = counter.signalColor
+	out.Rate = counter.speed()
 
 	return out
 }
@@ -144,6 +158,11 @@ func (counter *Counter) normalOutput(t time.Time) *CounterOutput {
 	progress := (float64(diff) / float64(counter.state.duration))
 	expired := diff.Seconds() < 1
 
+	fraction := float64(diff-diff.Truncate(time.Second)) / float64(time.Second)
+	if fraction < 0 {
+		fraction = -fraction
+	}
+
 	if expired {
 		hours = 0
 		minutes = 0
@@ -173,6 +192,7 @@ func (counter *Counter) normalOutput(t time.Time) *CounterOutput {
 		Countdown: counter.countdown,
 		Paused:    counter.paused,
 		Expired:   expired,
+		Days:      hours / 24,
 		Hours:     hours,
 		Minutes:   minutes,
 		Seconds:   seconds,
@@ -181,11 +201,30 @@ func (counter *Counter) normalOutput(t time.Time) *CounterOutput {
 		Icon:      icon,
 		Progress:  progress,
 		Diff:      diff,
+		Fraction:  fraction,
+	}
+
+	if len(counter.laps) > 0 {
+		var prev time.Duration
+		for _, lap := range counter.laps {
+			out.Laps = append(out.Laps, lap-prev)
+			prev = lap
+		}
+		out.LapTime = diff - prev
 	}
 
 	return out
 }
 
+// Lap records a lap on a running count up
+func (counter *Counter) Lap(t time.Time) error {
+	if !counter.active || counter.countdown || counter.media != nil || counter.slave != nil {
+		return fmt.Errorf("laps can only be recorded on count ups")
+	}
+	counter.laps = append(counter.laps, counter.Diff(t))
+	return nil
+}
+
 func (counter *Counter) slaveOutput() *CounterOutput {
 	hours := counter.slave.hours
 	minutes := counter.slave.minutes
@@ -228,21 +267,24 @@ func secsToCompact(rawSecs int64) string {
 
 // Start begins counting time up or down
 func (counter *Counter) Start(countdown bool, timer time.Duration) {
+	// A new timer runs in real time
+	counter.rate = 0
 	s := counterState{
-		target:   time.Now().Add(timer).Truncate(time.Second),
+		target:   counter.now().Add(counter.unscale(timer)).Truncate(time.Second),
 		duration: timer,
 		left:     timer,
 	}
 	counter.state = &s
+	counter.laps = nil
 
 	counter.countdown = countdown
 
-	t := time.Now()
+	t := counter.now()
 
 	if counter.countdown {
-		counter.state.left = counter.state.target.Sub(t).Truncate(time.Second)
+		counter.state.left = counter.scale(counter.state.target.Sub(t)).Truncate(time.Second)
 	} else {
-		counter.state.left = t.Sub(counter.state.target).Truncate(time.Second)
+		counter.state.left = counter.scale(t.Sub(counter.state.target)).Truncate(time.Second)
 	}
 
 	counter.active = true
@@ -250,7 +292,7 @@ func (counter *Counter) Start(countdown bool, timer time.Duration) {
 
 // Target sets the target date and time for a counter
 func (counter *Counter) Target(target time.Time) {
-	timer := target.Sub(time.Now())
+	timer := target.Sub(counter.now())
 	fmt.Printf("target: %v timer: %v\n", target, timer)
 	if timer < 0 {
 		counter.Start(false, timer)
@@ -313,7 +355,7 @@ func (counter *Counter) Modify(delta time.Duration) {
 	}
 
 	s := counterState{
-		target:   counter.state.target.Add(delta),
+		target:   counter.state.target.Add(counter.unscale(delta)),
 		duration: counter.state.duration + delta,
 		left:     counter.state.left + delta,
 	}
@@ -324,9 +366,10 @@ func (counter *Counter) Modify(delta time.Duration) {
 func (counter *Counter) Stop() {
 	counter.active = false
 	counter.paused = false
+	counter.rate = 0
 
 	s := counterState{
-		target:   time.Now(),
+		target:   counter.now(),
 		duration: time.Millisecond,
 		left:     time.Millisecond,
 	}
@@ -339,11 +382,11 @@ func (counter *Counter) Pause() {
 	if counter.paused {
 		return
 	}
-	t := time.Now()
+	t := counter.now()
 	if counter.countdown {
-		counter.state.left = counter.state.target.Sub(t).Truncate(time.Second)
+		counter.state.left = counter.scale(counter.state.target.Sub(t)).Truncate(time.Second)
 	} else {
-		counter.state.left = t.Sub(counter.state.target).Truncate(time.Second)
+		counter.state.left = counter.scale(t.Sub(counter.state.target)).Truncate(time.Second)
 	}
 	counter.paused = true
 }
@@ -354,24 +397,69 @@ func (counter *Counter) Resume() {
 	if !counter.paused {
 		return
 	}
-	t := time.Now()
+	t := counter.now()
 	if counter.countdown {
-		counter.state.target = t.Add(counter.state.left).Truncate(time.Second)
+		counter.state.target = t.Add(counter.unscale(counter.state.left)).Truncate(time.Second)
 	} else {
-		counter.state.target = t.Add(-counter.state.left).Truncate(time.Second)
+		counter.state.target = t.Add(-counter.unscale(counter.state.left)).Truncate(time.Second)
 	}
 	counter.paused = false
 }
 
+// SetRate changes the counting speed, eg. 2 runs the counter at double speed.
+// The current counter value is kept and the target is moved to match the new rate.
+func (counter *Counter) SetRate(rate float64) error {
+	if rate <= 0 || rate > maxRate {
+		return fmt.Errorf("rate %v out of range (0-%v)", rate, maxRate)
+	}
+	running := counter.active && !counter.paused && counter.media == nil && counter.slave == nil
+	t := counter.now()
+	diff := counter.Diff(t)
+
+	counter.rate = rate
+	if running {
+		if counter.countdown {
+			counter.state.target = t.Add(counter.unscale(diff))
+		} else {
+			counter.state.target = t.Add(-counter.unscale(diff))
+		}
+	}
+	return nil
+}
+
+// speed returns the counting speed, 1 for real time
+func (counter *Counter) speed() float64 {
+	if counter.rate <= 0 {
+		return 1
+	}
+	return counter.rate
+}
+
+// scale converts real time to counter time
+func (counter *Counter) scale(d time.Duration) time.Duration {
+	if counter.rate <= 0 || counter.rate == 1 {
+		return d
+	}
+	return time.Duration(float64(d) * counter.rate)
+}
+
+// unscale converts counter time to real time
+func (counter *Counter) unscale(d time.Duration) time.Duration {
+	if counter.rate <= 0 || counter.rate == 1 {
+		return d
+	}
+	return time.Duration(float64(d) / counter.rate)
+}
+
 // Diff gives a time difference to current time that can be used to format clock output strings
 func (counter *Counter) Diff(t time.Time) time.Duration {
 	if counter.paused {
 		return counter.state.left
 	}
 	if counter.countdown {
-		return counter.state.target.Sub(t)
+		return counter.scale(counter.state.target.Sub(t))
 	}
-	return t.Sub(counter.state.target)
+	return counter.scale(t.Sub(counter.state.target))
 }
 
 func (counter *Counter) setAutoColor(c color.RGBA, state int) {
@@ -381,6 +469,22 @@ func (counter *Counter) setAutoColor(c color.RGBA, state int) {
 	}
 }
 
+// expired tells if a countdown has reached its target
+func (counter *Counter) expired(t time.Time) bool {
+	if !counter.active || !counter.countdown || counter.media != nil || counter.slave != nil {
+		return false
+	}
+	return counter.Diff(t) <= 0
+}
+
+// now returns the current time from the counter time source
+func (counter *Counter) now() time.Time {
+	if counter.timeSource == nil {
+		return time.Now()
+	}
+	return counter.timeSource.Now()
+}
+
 func abs(i int) int {
 	if i < 0 {
 		return -i
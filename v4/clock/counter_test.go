@@ -0,0 +1,299 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestCounter(fake *FakeTime) *Counter {
+	return &Counter{
+		state:      &counterState{},
+		timeSource: fake,
+	}
+}
+
+func TestCounterOperations(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(c *Counter, fake *FakeTime)
+		active  bool
+		paused  bool
+		expired bool
+		text    string
+		diff    time.Duration
+	}{
+		{
+			name:   "inactive",
+			run:    func(c *Counter, fake *FakeTime) {},
+			active: false,
+			text:   "",
+		},
+		{
+			name: "countdown start",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				fake.Advance(90 * time.Second)
+			},
+			active: true,
+			text:   "00:08:30",
+			diff:   8*time.Minute + 30*time.Second,
+		},
+		{
+			name: "countup start",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(false, 0)
+				fake.Advance(time.Hour + 2*time.Minute + 3*time.Second)
+			},
+			active: true,
+			text:   "01:02:03",
+			diff:   time.Hour + 2*time.Minute + 3*time.Second,
+		},
+		{
+			name: "pause holds the countdown",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				fake.Advance(time.Minute)
+				c.Pause()
+				fake.Advance(5 * time.Minute)
+			},
+			active: true,
+			paused: true,
+			text:   "00:09:00",
+			diff:   9 * time.Minute,
+		},
+		{
+			name: "resume continues from the paused time",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				fake.Advance(time.Minute)
+				c.Pause()
+				fake.Advance(5 * time.Minute)
+				c.Resume()
+				fake.Advance(time.Minute)
+			},
+			active: true,
+			text:   "00:08:00",
+			diff:   8 * time.Minute,
+		},
+		{
+			name: "modify adds time",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				c.Modify(2 * time.Minute)
+				fake.Advance(time.Minute)
+			},
+			active: true,
+			text:   "00:11:00",
+			diff:   11 * time.Minute,
+		},
+		{
+			name: "modify while paused",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				c.Pause()
+				c.Modify(-3 * time.Minute)
+				fake.Advance(time.Minute)
+			},
+			active: true,
+			paused: true,
+			text:   "00:07:00",
+			diff:   7 * time.Minute,
+		},
+		{
+			name: "countdown expires",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, time.Minute)
+				fake.Advance(90 * time.Second)
+			},
+			active:  true,
+			expired: true,
+			text:    "00:00:00",
+			diff:    -30 * time.Second,
+		},
+		{
+			name: "stop deactivates",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				fake.Advance(time.Minute)
+				c.Stop()
+			},
+			active: false,
+			text:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeTime(testStart)
+			c := newTestCounter(fake)
+			tt.run(c, fake)
+
+			out := c.Output(fake.Now())
+			if out.Active != tt.active {
+				t.Errorf("active = %v, want %v", out.Active, tt.active)
+			}
+			if out.Paused != tt.paused {
+				t.Errorf("paused = %v, want %v", out.Paused, tt.paused)
+			}
+			if out.Expired != tt.expired {
+				t.Errorf("expired = %v, want %v", out.Expired, tt.expired)
+			}
+			if out.Text != tt.text {
+				t.Errorf("text = %q, want %q", out.Text, tt.text)
+			}
+			if out.Active && out.Diff != tt.diff {
+				t.Errorf("diff = %v, want %v", out.Diff, tt.diff)
+			}
+		})
+	}
+}
+
+func TestCounterLaps(t *testing.T) {
+	fake := NewFakeTime(testStart)
+	c := newTestCounter(fake)
+
+	c.Start(true, 10*time.Minute)
+	if err := c.Lap(fake.Now()); err == nil {
+		t.Errorf("lap on a countdown did not return an error")
+	}
+
+	c.Start(false, 0)
+	fake.Advance(30 * time.Second)
+	if err := c.Lap(fake.Now()); err != nil {
+		t.Fatalf("Lap: %v", err)
+	}
+	fake.Advance(20 * time.Second)
+	c.Pause()
+	fake.Advance(time.Hour) // Paused time is not part of the lap
+	c.Resume()
+	fake.Advance(25 * time.Second)
+	if err := c.Lap(fake.Now()); err != nil {
+		t.Fatalf("Lap: %v", err)
+	}
+	fake.Advance(10 * time.Second)
+
+	out := c.Output(fake.Now())
+	want := []time.Duration{30 * time.Second, 45 * time.Second}
+	if len(out.Laps) != len(want) {
+		t.Fatalf("laps = %v, want %v", out.Laps, want)
+	}
+	for i := range want {
+		if out.Laps[i] != want[i] {
+			t.Errorf("lap %d = %v, want %v", i+1, out.Laps[i], want[i])
+		}
+	}
+	if out.LapTime != 10*time.Second {
+		t.Errorf("current lap = %v, want 10s", out.LapTime)
+	}
+	if out.Diff != 85*time.Second {
+		t.Errorf("split time = %v, want 1m25s", out.Diff)
+	}
+
+	c.Start(false, 0)
+	if out := c.Output(fake.Now()); len(out.Laps) != 0 {
+		t.Errorf("laps after a restart = %v, want none", out.Laps)
+	}
+}
+
+func TestCounterRate(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(c *Counter, fake *FakeTime)
+		text string
+		rate float64
+	}{
+		{
+			name: "double speed",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				c.SetRate(2)
+				fake.Advance(time.Minute)
+			},
+			text: "00:08:00",
+			rate: 2,
+		},
+		{
+			name: "rate change keeps the value",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				c.SetRate(2)
+				fake.Advance(time.Minute)
+				c.SetRate(0.5)
+				fake.Advance(2 * time.Minute)
+			},
+			text: "00:07:00",
+			rate: 0.5,
+		},
+		{
+			name: "pause and resume at a rate",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(false, 0)
+				c.SetRate(2)
+				fake.Advance(time.Minute)
+				c.Pause()
+				fake.Advance(time.Hour)
+				c.Resume()
+				fake.Advance(30 * time.Second)
+			},
+			text: "00:03:00",
+			rate: 2,
+		},
+		{
+			name: "modify at a rate",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				c.SetRate(2)
+				c.Modify(2 * time.Minute)
+				fake.Advance(time.Minute)
+			},
+			text: "00:10:00",
+			rate: 2,
+		},
+		{
+			name: "start returns to real time",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(true, 10*time.Minute)
+				c.SetRate(2)
+				c.Start(true, 10*time.Minute)
+				fake.Advance(time.Minute)
+			},
+			text: "00:09:00",
+			rate: 1,
+		},
+		{
+			name: "stop returns to real time",
+			run: func(c *Counter, fake *FakeTime) {
+				c.Start(false, 0)
+				c.SetRate(2)
+				c.Stop()
+				c.Start(false, 0)
+				fake.Advance(time.Minute)
+			},
+			text: "00:01:00",
+			rate: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeTime(testStart)
+			c := newTestCounter(fake)
+			tt.run(c, fake)
+
+			out := c.Output(fake.Now())
+			if out.Text != tt.text {
+				t.Errorf("text = %q, want %q", out.Text, tt.text)
+			}
+			if out.Rate != tt.rate {
+				t.Errorf("rate = %v, want %v", out.Rate, tt.rate)
+			}
+		})
+	}
+
+	c := newTestCounter(NewFakeTime(testStart))
+	for _, rate := range []float64{0, -1, maxRate + 1} {
+		if err := c.SetRate(rate); err == nil {
+			t.Errorf("SetRate(%v) did not return an error", rate)
+		}
+	}
+}
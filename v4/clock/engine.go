@@ -16,6 +16,7 @@ import (
 	db "runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -25,6 +26,7 @@ const Version = "4.0.0"
 // State feedback timer
 const stateTimer = time.Second / 2
 const udpTimer = time.Second / 10
+const checkTimer = time.Second / 10
 const flashDuration = 200 * time.Millisecond
 
 // Will get overridden by ldflags in Makefile
@@ -44,9 +46,20 @@ type SourceOptions struct {
 	LTC           bool   `long:"ltc" description:"Enable LTC as a source"`
 	Timer         bool   `long:"timer" description:"Enable timer counter as a source"`
 	Tod           bool   `long:"tod" description:"Enable time-of-day as a source"`
+	Inputs        string `long:"inputs" description:"Ordered list of inputs, the first active one is displayed: ltc, timer, counter:N, tod[:ZONE], date[:ZONE], utc, show, label:TEXT. Overrides ltc, timer and tod"`
 	TimeZone      string `long:"timezone" description:"Time zone to use for ToD display" default:"Europe/Helsinki"`
 	Hidden        bool   `long:"hidden" description:"Hide this time source"`
 	OvertimeColor string `long:"overtime-color" description:"Background color for overtime countdowns, in HTML format #FFFFFF" default:"#FF0000"`
+
+	OvertimeCountMode  string `long:"overtime-count-mode" description:"Behaviour for expired countdown timer counts: zero, blank or continue, leave empty to use the global setting"`
+	OvertimeVisibility string `long:"overtime-visibility" description:"Extra visibility for overtime timers: blink, background, both or none, leave empty to use the global setting"`
+	TimeFormat         string `long:"time-format" description:"Time-of-day format: 12h or 24h, leave empty to use the global setting"`
+	HideSeconds        bool   `long:"hide-seconds" description:"Hide the seconds from the time-of-day display"`
+	SubSeconds         int    `long:"sub-seconds" description:"Show tenths of a second on timers under this many seconds, 0 disables" default:"0"`
+	Hundredths         bool   `long:"hundredths" description:"Show hundredths instead of tenths of a second with sub-seconds"`
+	Format             string `long:"format" description:"Format string for the clock text, eg. %H:%M or %-Mm %Ss, leave empty for the default"`
+	BackTime           bool   `long:"back-time" description:"Show the time of day a running countdown will end"`
+	OvertimeFormat     string `long:"overtime-format" description:"Format string for expired countdowns, eg. -%M:%S, leave empty to use the normal format"`
 }
 
 // EngineOptions contains all common options for clock.Engines
@@ -80,10 +93,30 @@ type EngineOptions struct {
 	SignalThresholdEnd     int    `long:"signal-threshold-end" description:"Threshold for medium color transition (seconds)" default:"60"`
 	SignalHardware         int    `long:"signal-hw-group" description:"Hardware signal group number" default:"1"`
 
-	Source1 *SourceOptions `group:"1st clock display source" namespace:"source1"`
-	Source2 *SourceOptions `group:"2nd clock display source" namespace:"source2"`
-	Source3 *SourceOptions `group:"3rd clock display source" namespace:"source3"`
-	Source4 *SourceOptions `group:"4th clock display source" namespace:"source4"`
+	Thresholds []string `long:"timer-thresholds" value-name:"PROFILE" description:"Signal color steps for a counter: counter seconds|percent% #RRGGBB [on/off], ..., can be repeated"`
+	Links      []string `long:"timer-link" value-name:"LINK" description:"Counter following another with an offset and scale: counter source [[-]HH:MM:SS] [scale], can be repeated"`
+
+	StateFile  string     `long:"state-file" description:"File to persist timer and source state across restarts, leave empty to disable"`
+	TimeSource TimeSource `no-flag:"true"` // Time source for the engine, defaults to the system clock
+
+	Counters int              `long:"counters" description:"Number of timer counters" default:"10"`
+	Sources  []*SourceOptions `no-flag:"true"` // Clock display sources, configured as source1, source2, ...
+
+	Groups []string `long:"group" value-name:"GROUP" description:"Named counter group: name counters, eg. stage 1,3, can be repeated"`
+
+	HardOut string `long:"hard-out" description:"Time the show must end by as HH:MM:SS or an ISO 8601 date-time, compared against the remaining rundown time"`
+
+	Presets []string `long:"preset" value-name:"PRESET" description:"Timer preset: name;duration;direction;title;color;background;thresholds, can be repeated"`
+
+	Schedule []string `long:"schedule" value-name:"ENTRY" description:"Run a command at a time of day: HH:MM[:SS] days source command, can be repeated"`
+
+	ExpireActions    []string `long:"timer-expire-action" value-name:"ACTION" description:"Run a command when a countdown expires: counter command, can be repeated"`
+	ThresholdActions []string `long:"timer-threshold-action" value-name:"ACTION" description:"Run a command when a countdown reaches a threshold: counter seconds command, can be repeated"`
+
+	Webhooks       []string `long:"webhook" value-name:"URL" description:"URL to POST counter events to as JSON, can be repeated"`
+	WebhookTimeout int      `long:"webhook-timeout" description:"Timeout for webhook requests (ms)" default:"2000"`
+	WebhookRetries int      `long:"webhook-retries" description:"Number of retries for failed webhook requests" default:"3"`
+	EventCommand   string   `long:"event-command" description:"Command to run on counter events, receives the event as JSON on stdin"`
 }
 
 // Clock engine state constants
@@ -96,12 +129,13 @@ const (
 	LTC       = iota // LTC display
 	Media     = iota // Playing media counter
 	Slave     = iota // Displaying slaved output
+	Date      = iota // Display current date
+	Label     = iota // Display fixed text
+	OverUnder = iota // Display the show over/under time against the hard out
 )
 
 // Misc constants
 const (
-	numCounters      = 10 // Number of distinct counters to initialize
-	numSources       = 4
 	PrimaryCounter   = 0 // Main counter that replaces the ToD display on the round clock when active
 	SecondaryCounter = 1 // Secondary counter that is displayed in the tally message space on the round clock
 )
@@ -117,9 +151,10 @@ type ltcData struct {
 
 // Engine contains the state machine for clock-8001
 type Engine struct {
+	mutex                  sync.Mutex // Serializes all access to the engine state
 	mode                   int        // Main display mode
 	Counters               []*Counter // Timer counters
-	sources                []*source  // Time sources for 1-3 displays
+	sources                []*source  // Clock display sources
 	displaySeconds         bool
 	flashPeriod            int
 	clockServer            *Server
@@ -162,11 +197,34 @@ type Engine struct {
 	signalHardware         int
 	overtimeCountMode      string
 	overtimeVisibility     string
+	tallyTimer             *timer.Timer // Clears the OSC tally message
+	ltcTimer               *timer.Timer // LTC signal loss
+	mittiTimer             *timer.Timer // Mitti media feedback timeout
+	milluminTimer          *timer.Timer // Millumin media feedback timeout
+	flashTimer             *timer.Timer // Screen flash duration
+	timeSource             TimeSource   // Source for the current time
+	stateFile              string       // Path for persisting the engine state
+	savedState             []byte       // Last state written to stateFile
+	rundown                rundown      // Rundown segments and position
+	hardOut                string       // Time the show must end by, resolved against the projected end, empty if not set
+	commands               *Server      // Decodes commands run by the engine itself
+	schedule               []*scheduleEntry
+	scheduleID             int // Id of the last added schedule entry
+	presets                []*preset
+	groups                 []*counterGroup
+	links                  []*counterLink      // Counters following other counters
+	actions                []*timerAction      // Countdown expiry and threshold actions
+	events                 EventBus            // Counter state transitions
+	eventStates            []eventState        // Threshold crossing state for each counter
+	timingLog              []Event             // Logged counter events for the timing report
+	history                [][]counterSnapshot // Undo history for each counter
+	defaultThresholds      []thresholdProfile  // Threshold profiles from the configuration for each counter
 }
 
 // Clock contains the state of a single component clock / timer
 type Clock struct {
 	Text        string     // Normal clock representation HH:MM:SS(:FF)
+	Days        int        // Whole days on the clock, included in Hours
 	Hours       int        // Hours on the clock
 	Minutes     int        // Minutes on the clock
 	Seconds     int        // Seconds on the clock
@@ -184,6 +242,17 @@ type Clock struct {
 	HideHours   bool       // Should the hour field of the time be displayed for this clock.
 	HideSeconds bool       // Should seconds be shown for this clock
 	SignalColor color.RGBA
+	SubSeconds  bool          // Show tenths of seconds on the timer
+	Hundredths  bool          // Show hundredths instead of tenths with SubSeconds
+	Formatted   bool          // Text has been built from the source format string
+	EndTime     string        // Time of day a running countdown ends as HH:MM:SS or HH:MM:SS AM/PM, if back-timing is enabled
+	Fraction    float64       // Fraction of the current second 0-1, for sub-second display
+	Lap         int           // Number of the current stopwatch lap, 0 without laps
+	LapTime     time.Duration // Elapsed time on the current lap
+	LastLap     time.Duration // Duration of the previous lap
+	Counter     int           // Timer counter number of the source
+	TimeZone    string        // Time zone name of the source
+	Inputs      string        // Input list of the source in priority order
 }
 
 // State is a snapshot of the clock representation on the time State() was called
@@ -200,6 +269,8 @@ type State struct {
 	TitleBGColor        color.RGBA  // Background color for clock title text
 	ScreenFlash         bool        // Set to true if the screen should be flashed white
 	HardwareSignalColor color.RGBA
+	Rundown             RundownState // Rundown position
+	Show                ShowState    // Show over/under against the hard out
 }
 
 // MakeEngine creates a clock engine
@@ -225,6 +296,11 @@ func MakeEngine(options *EngineOptions) (*Engine, error) {
 		signalHardware:         options.SignalHardware,
 		overtimeCountMode:      options.OvertimeCountMode,
 		overtimeVisibility:     options.OvertimeVisibility,
+		timeSource:             options.TimeSource,
+		stateFile:              options.StateFile,
+	}
+	if engine.timeSource == nil {
+		engine.timeSource = systemTime{}
 	}
 	uuid, err := machineid.ProtectedID("clock-8001")
 	if err != nil {
@@ -241,32 +317,80 @@ func MakeEngine(options *EngineOptions) (*Engine, error) {
 		engine.signalColors[i] = c
 	}
 
-	log.Printf("Source1: %v", options.Source1)
-	log.Printf("Source2: %v", options.Source2)
-	log.Printf("Source3: %v", options.Source3)
-	log.Printf("Source4: %v", options.Source4)
+	if options.Counters < 1 {
+		return nil, fmt.Errorf("invalid number of counters: %d", options.Counters)
+	}
+	if len(options.Sources) == 0 {
+		return nil, fmt.Errorf("no clock display sources configured")
+	}
+	for _, c := range []int{options.Mitti, options.Millumin, options.UDPTimer1, options.UDPTimer2} {
+		if c < 0 || c >= options.Counters {
+			return nil, fmt.Errorf("counter number %d out of range (have %d counters)", c, options.Counters)
+		}
+	}
+
+	for i, s := range options.Sources {
+		log.Printf("Source%d: %v", i+1, s)
+	}
 
 	ltc := ltcData{hours: 0}
 	engine.ltc = &ltc
 
 	engine.printVersion()
-	engine.initCounters()
+	engine.initCounters(options.Counters)
+	engine.initTimers()
 
 	engine.mittiCounter = engine.Counters[options.Mitti]
 	engine.milluminCounter = engine.Counters[options.Millumin]
 
 	log.Printf("Media counters - Mitti: %d, Millumin %d", options.Mitti, options.Millumin)
 
-	sources := make([]*SourceOptions, 4)
-	sources[0] = options.Source1
-	sources[1] = options.Source2
-	sources[2] = options.Source3
-	sources[3] = options.Source4
-
-	if err := engine.initSources(sources); err != nil {
+	if err := engine.initSources(options.Sources); err != nil {
 		log.Printf("Error initializing engine clock sources: %v", err)
 		return nil, err
 	}
+
+	if err := engine.setHardOut(options.HardOut); err != nil {
+		return nil, err
+	}
+
+	engine.commands = MakeServer(nil, engine.uuid)
+	for _, spec := range options.Schedule {
+		if err := engine.addSchedule(spec); err != nil {
+			return nil, fmt.Errorf("schedule entry %q: %v", spec, err)
+		}
+	}
+
+	if err := engine.loadTimerActions(options.ExpireActions, false); err != nil {
+		return nil, err
+	}
+	if err := engine.loadTimerActions(options.ThresholdActions, true); err != nil {
+		return nil, err
+	}
+
+	if err := engine.loadThresholds(options.Thresholds); err != nil {
+		return nil, err
+	}
+
+	if err := engine.loadLinks(options.Links); err != nil {
+		return nil, err
+	}
+
+	if err := engine.loadPresets(options.Presets); err != nil {
+		return nil, err
+	}
+
+	if err := engine.loadGroups(options.Groups); err != nil {
+		return nil, err
+	}
+
+	engine.initEventHooks(options)
+
+	if engine.stateFile != "" {
+		if err := engine.restoreState(); err != nil {
+			log.Printf("Error restoring clock state from %s: %v", engine.stateFile, err)
+		}
+	}
 	engine.initOSC(options)
 
 	// Led flash cycle
@@ -283,7 +407,6 @@ func MakeEngine(options *EngineOptions) (*Engine, error) {
 	engine.prepareInfo()
 
 	engine.infoTimer = timer.NewTimer(time.Duration(options.ShowInfo) * time.Second)
-	go engine.infoTimeout()
 	engine.showInfo = true
 	fmt.Printf(engine.info)
 
@@ -299,13 +422,19 @@ func MakeEngine(options *EngineOptions) (*Engine, error) {
 			engine.udpDests = make([]*feedbackDestination, 2)
 			engine.udpDests[0] = initFeedback("255.255.255.255:36700")
 			engine.udpDests[1] = initFeedback("255.255.255.255:36701")
-		} else {
-			log.Printf("Initializing UDP time receiver")
-			// Receive timers
-			go engine.listenUDPTime()
 		}
 	}
 
+	// The engine is fully initialized, start the goroutines using it
+	go engine.infoTimeout()
+	go engine.run()
+	engine.startOSC(options)
+	if options.UDPTime != "off" && options.UDPTime != "send" {
+		log.Printf("Initializing UDP time receiver")
+		// Receive timers
+		go engine.listenUDPTime()
+	}
+
 	return &engine, nil
 }
 
@@ -334,7 +463,9 @@ func (engine *Engine) listenUDPTime() {
 		if msg.OverTime {
 			icon = "+"
 		}
+		engine.mutex.Lock()
 		engine.udpCounters[t].SetSlave(0, msg.Minutes, msg.Seconds, true, icon)
+		engine.mutex.Unlock()
 	}
 }
 
@@ -352,7 +483,9 @@ func (engine *Engine) prepareInfo() {
 
 func (engine *Engine) infoTimeout() {
 	for range engine.infoTimer.C {
+		engine.mutex.Lock()
 		engine.showInfo = false
+		engine.mutex.Unlock()
 	}
 }
 
@@ -371,194 +504,370 @@ func (engine *Engine) runOSC() {
 // Listen for OSC messages
 func (engine *Engine) listen() {
 	oscChan := engine.clockServer.Listen()
-	tallyTimer := timer.NewTimer(engine.timeout)
-	tallyTimer.Stop()
-	ltcTimer := timer.NewTimer(engine.timeout)
-	ltcTimer.Stop() // Needed to prevent a timeout at the start
-
-	mittiTimer := timer.NewTimer(updateTimeout)
-	milluminTimer := timer.NewTimer(updateTimeout)
-	stateTicker := time.NewTicker(stateTimer)
-	udpTicker := time.NewTicker(udpTimer)
-	flashTimer := timer.NewTimer(flashDuration)
 
 	for {
 		select {
 		case message := <-oscChan:
 			// New OSC message received
 			debug.Printf("Got new osc data: %v\n", message)
-			switch message.Type {
-			case "timerStart":
-				time := time.Duration(message.CountdownMessage.Seconds) * time.Second
-				engine.StartCounter(message.Counter, message.Countdown, time)
-			case "timerModify":
-				time := time.Duration(message.CountdownMessage.Seconds) * time.Second
-				engine.ModifyCounter(message.Counter, time)
-			case "timerStop":
-				engine.StopCounter(message.Counter)
-			case "timerTarget":
-				engine.TargetCounter(message.Counter, message.Data, message.Countdown)
-			case "timerPause":
-				engine.PauseCounter(message.Counter)
-			case "timerResume":
-				engine.ResumeCounter(message.Counter)
-			case "display":
-				msg := message.DisplayMessage
-				log.Printf("Setting tally message to: %s", msg.Text)
-
-				engine.message = msg.Text
-				engine.messageColor = &color.RGBA{
-					R: uint8(msg.ColorRed),
-					G: uint8(msg.ColorBlue),
-					B: uint8(msg.ColorGreen),
-					A: 255,
-				}
-
-				engine.messageBG = &color.RGBA{
-					R: 0,
-					G: 0,
-					B: 0,
-					A: 255,
-				}
-
-				// Mark the OSC message state as active
-				engine.oscTally = true
-
-				// Reset the timer that will clear the message when it expires
-				tallyTimer.Reset(engine.timeout)
-
-			case "displayText":
-				msg := message.DisplayTextMessage
-				log.Printf("Displaying text: %v", msg)
-
-				engine.message = msg.text
-				engine.messageColor = &color.RGBA{
-					R: uint8(msg.r),
-					G: uint8(msg.g),
-					B: uint8(msg.b),
-					A: uint8(msg.a),
-				}
-				engine.messageBG = &color.RGBA{
-					R: uint8(msg.bgR),
-					G: uint8(msg.bgG),
-					B: uint8(msg.bgB),
-					A: uint8(msg.bgA),
-				}
-				engine.oscTally = true
-				if msg.time != 0 {
-					tallyTimer.Reset(time.Duration(msg.time) * time.Second)
-				}
-			case "pause":
-				engine.Pause()
-			case "resume":
-				engine.Resume()
-			case "hideAll":
-				engine.hideAll()
-			case "showAll":
-				engine.showAll()
-			case "secondsOff":
-				engine.displaySeconds = false
-			case "secondsOn":
-				engine.displaySeconds = true
-			case "setTime":
-				engine.setTime(message.Data)
-			case "LTC":
-				if engine.ltcEnabled {
-					engine.setLTC(message.Data)
-					ltcTimer.Reset(engine.timeout)
-				}
-			case "dualText":
-				engine.message = fmt.Sprintf("%-.8s", message.Data)
-			case "mitti":
-				mittiTimer.Reset(updateTimeout)
-
-				m := message.MediaMessage
-				engine.mittiCounter.SetMedia(m.hours, m.minutes, m.seconds, m.frames, time.Duration(m.remaining)*time.Second, m.progress, m.paused, m.looping)
-			case "mittiReset":
-				engine.mittiCounter.ResetMedia()
-			case "millumin:":
-				milluminTimer.Reset(updateTimeout)
-
-				m := message.MediaMessage
-				engine.milluminCounter.SetMedia(m.hours, m.minutes, m.seconds, m.frames, time.Duration(m.remaining)*time.Second, m.progress, m.paused, m.looping)
-			case "milluminReset":
-				engine.milluminCounter.ResetMedia()
-			case "background":
-				// FIXME: non semantic ugliness
-				engine.background = message.Counter
-			case "sourceHide":
-				if message.Counter >= 0 &&
-					message.Counter < len(engine.sources) {
-
-					engine.sources[message.Counter].hidden = true
-				}
-			case "sourceShow":
-				if message.Counter >= 0 &&
-					message.Counter < len(engine.sources) {
-					engine.sources[message.Counter].hidden = false
-				}
-			case "sourceTitle":
-				if message.Counter >= 0 &&
-					message.Counter < len(engine.sources) {
-
-					engine.sources[message.Counter].title = message.Data
-				}
-			case "showInfo":
-				engine.showInfo = true
-				engine.infoTimer.Reset(time.Duration(message.Counter) * time.Second)
-			case "sourceColors":
-				if message.Counter >= 0 &&
-					message.Counter < len(engine.sources) &&
-					len(message.Colors) == 2 {
-
-					log.Printf("Setting source %d colors: %v - %v", message.Counter+1, message.Colors[0], message.Colors[1])
-					engine.SetSourceColors(message.Counter, message.Colors[0], message.Colors[1])
-				}
-			case "titleColors":
-				if len(message.Colors) == 2 {
-					log.Printf("Setting title colors: %v - %v", message.Colors[0], message.Colors[1])
-					engine.SetTitleColors(message.Colors[0], message.Colors[1])
-				}
-			case "screenFlash":
-				engine.screenFlash = true
-				flashTimer.Reset(flashDuration)
-			case "timerSignal":
-				if message.Counter >= 0 &&
-					message.Counter < len(engine.sources) &&
-					len(message.Colors) == 1 {
-					engine.Counters[message.Counter].signalColor = message.Colors[0]
-				}
-			case "hardwareSignal":
-				if message.Counter == engine.signalHardware && len(message.Colors) == 1 {
-					engine.signalHardwareColor = message.Colors[0]
-				}
-			}
-			// We have received a osc command, so stop the version display
-			engine.initialized = true
-
-		case <-flashTimer.C:
+			engine.mutex.Lock()
+			engine.handleMessage(message)
+			engine.mutex.Unlock()
+		case <-engine.flashTimer.C:
+			engine.mutex.Lock()
 			engine.screenFlash = false
-		case <-mittiTimer.C:
+			engine.mutex.Unlock()
+		case <-engine.mittiTimer.C:
+			engine.mutex.Lock()
 			engine.mittiCounter.ResetMedia()
-		case <-milluminTimer.C:
+			engine.mutex.Unlock()
+		case <-engine.milluminTimer.C:
+			engine.mutex.Lock()
 			engine.milluminCounter.ResetMedia()
-		case <-tallyTimer.C:
+			engine.mutex.Unlock()
+		case <-engine.tallyTimer.C:
 			// OSC message timeout
+			engine.mutex.Lock()
 			engine.message = ""
 			engine.oscTally = false
-		case <-ltcTimer.C:
+			engine.mutex.Unlock()
+		case <-engine.ltcTimer.C:
 			// LTC message timeout
+			engine.mutex.Lock()
 			engine.ltcTimeout = true
+			engine.mutex.Unlock()
+		}
+	}
+}
+
+// run is the engine loop for the periodic counter checks, feedback and state
+// persistence. It is started with and without OSC control.
+func (engine *Engine) run() {
+	udpTicker := time.NewTicker(udpTimer)
+	checkTicker := time.NewTicker(checkTimer)
+	stateTicker := time.NewTicker(stateTimer)
+
+	for {
+		select {
+		case <-udpTicker.C:
+			engine.sendUDPTimers()
+		case <-checkTicker.C:
+			// Schedule, timer actions, events, rundown auto-advance and timer links
+			engine.mutex.Lock()
+			engine.checkCounters()
+			engine.mutex.Unlock()
 		case <-stateTicker.C:
 			// Send OSC feedback
 			state := engine.State()
 			if err := engine.sendState(state); err != nil {
 				log.Printf("Error sending osc state: %v", err)
 			}
-		case <-udpTicker.C:
-			engine.sendUDPTimers()
+			engine.saveState()
+		}
+	}
+}
+
+// checkCounters runs the periodic checks for counter state transitions
+func (engine *Engine) checkCounters() {
+	t := engine.timeSource.Now()
+	engine.syncLinks()
+	engine.checkEvents(t)
+	engine.checkTimerActions(t)
+	engine.checkRundown(t)
+	engine.checkSchedule(t)
+}
+
+// initTimers creates the timers used for expiring engine state set by OSC commands
+func (engine *Engine) initTimers() {
+	engine.tallyTimer = timer.NewTimer(engine.timeout)
+	engine.tallyTimer.Stop()
+	engine.ltcTimer = timer.NewTimer(engine.timeout)
+	engine.ltcTimer.Stop() // Needed to prevent a timeout at the start
+
+	engine.mittiTimer = timer.NewTimer(updateTimeout)
+	engine.milluminTimer = timer.NewTimer(updateTimeout)
+	engine.flashTimer = timer.NewTimer(flashDuration)
+}
+
+// handleMessage applies a decoded clock message to the engine state.
+// The caller must hold engine.mutex.
+func (engine *Engine) handleMessage(message Message) {
+	switch message.Type {
+	case "timerStart":
+		time := time.Duration(message.CountdownMessage.Seconds) * time.Second
+		engine.startCounter(message.Counter, message.Countdown, time)
+	case "timerModify":
+		time := time.Duration(message.CountdownMessage.Seconds) * time.Second
+		engine.modifyCounter(message.Counter, time)
+	case "timerStop":
+		engine.stopCounter(message.Counter)
+	case "timerTarget":
+		engine.targetCounter(message.Counter, message.Data, message.Countdown)
+	case "timerPause":
+		engine.pauseCounter(message.Counter)
+	case "timerResume":
+		engine.resumeCounter(message.Counter)
+	case "display":
+		msg := message.DisplayMessage
+		log.Printf("Setting tally message to: %s", msg.Text)
+
+		engine.message = msg.Text
+		engine.messageColor = &color.RGBA{
+			R: uint8(msg.ColorRed),
+			G: uint8(msg.ColorBlue),
+			B: uint8(msg.ColorGreen),
+			A: 255,
+		}
+
+		engine.messageBG = &color.RGBA{
+			R: 0,
+			G: 0,
+			B: 0,
+			A: 255,
+		}
+
+		// Mark the OSC message state as active
+		engine.oscTally = true
+
+		// Reset the timer that will clear the message when it expires
+		engine.tallyTimer.Reset(engine.timeout)
+
+	case "displayText":
+		msg := message.DisplayTextMessage
+		log.Printf("Displaying text: %v", msg)
+
+		engine.message = msg.text
+		engine.messageColor = &color.RGBA{
+			R: uint8(msg.r),
+			G: uint8(msg.g),
+			B: uint8(msg.b),
+			A: uint8(msg.a),
+		}
+		engine.messageBG = &color.RGBA{
+			R: uint8(msg.bgR),
+			G: uint8(msg.bgG),
+			B: uint8(msg.bgB),
+			A: uint8(msg.bgA),
+		}
+		engine.oscTally = true
+		if msg.time != 0 {
+			engine.tallyTimer.Reset(time.Duration(msg.time) * time.Second)
+		}
+	case "pause":
+		engine.pause()
+	case "resume":
+		engine.resume()
+	case "hideAll":
+		engine.hideAll()
+	case "showAll":
+		engine.showAll()
+	case "secondsOff":
+		engine.setDisplaySeconds(false)
+	case "secondsOn":
+		engine.setDisplaySeconds(true)
+	case "sourceTimezone":
+		if err := engine.setSourceTimezone(message.Counter, message.Data); err != nil {
+			log.Printf("Error setting source time zone: %v", err)
+		}
+	case "sourceCounter":
+		// The counter number is passed as text in the message data
+		counter, _ := strconv.Atoi(message.Data)
+		if err := engine.setSourceCounter(message.Counter, counter); err != nil {
+			log.Printf("Error setting source counter: %v", err)
+		}
+	case "sourceInputs":
+		if err := engine.setSourceInputs(message.Counter, message.Data); err != nil {
+			log.Printf("Error setting source inputs: %v", err)
+		}
+	case "sourceOvertimeMode":
+		if err := engine.setSourceOvertimeMode(message.Counter, message.Data); err != nil {
+			log.Printf("Error setting source overtime count mode: %v", err)
+		}
+	case "sourceOvertimeVisibility":
+		if err := engine.setSourceOvertimeVisibility(message.Counter, message.Data); err != nil {
+			log.Printf("Error setting source overtime visibility: %v", err)
+		}
+	case "sourceFormat12h":
+		if err := engine.setSourceFormat(message.Counter, true); err != nil {
+			log.Printf("Error setting source time format: %v", err)
+		}
+	case "sourceFormat24h":
+		if err := engine.setSourceFormat(message.Counter, false); err != nil {
+			log.Printf("Error setting source time format: %v", err)
+		}
+	case "sourceSecondsOn":
+		if err := engine.setSourceSeconds(message.Counter, true); err != nil {
+			log.Printf("Error setting source seconds display: %v", err)
+		}
+	case "sourceSecondsOff":
+		if err := engine.setSourceSeconds(message.Counter, false); err != nil {
+			log.Printf("Error setting source seconds display: %v", err)
+		}
+	case "sourceColorsReset":
+		if err := engine.resetSourceColors(message.Counter); err != nil {
+			log.Printf("Error resetting source colors: %v", err)
+		}
+	case "sourceBackTimeOn":
+		if err := engine.setSourceBackTime(message.Counter, true); err != nil {
+			log.Printf("Error setting source back-timing: %v", err)
+		}
+	case "sourceBackTimeOff":
+		if err := engine.setSourceBackTime(message.Counter, false); err != nil {
+			log.Printf("Error setting source back-timing: %v", err)
+		}
+	case "setTime":
+		engine.setTime(message.Data)
+	case "LTC":
+		if engine.ltcEnabled {
+			engine.setLTC(message.Data)
+			engine.ltcTimer.Reset(engine.timeout)
+		}
+	case "dualText":
+		engine.message = fmt.Sprintf("%-.8s", message.Data)
+	case "mitti":
+		engine.mittiTimer.Reset(updateTimeout)
+
+		m := message.MediaMessage
+		engine.mittiCounter.SetMedia(m.hours, m.minutes, m.seconds, m.frames, time.Duration(m.remaining)*time.Second, m.progress, m.paused, m.looping)
+	case "mittiReset":
+		engine.mittiCounter.ResetMedia()
+	case "millumin:":
+		engine.milluminTimer.Reset(updateTimeout)
+
+		m := message.MediaMessage
+		engine.milluminCounter.SetMedia(m.hours, m.minutes, m.seconds, m.frames, time.Duration(m.remaining)*time.Second, m.progress, m.paused, m.looping)
+	case "milluminReset":
+		engine.milluminCounter.ResetMedia()
+	case "background":
+		// FIXME: non semantic ugliness
+		engine.background = message.Counter
+	case "sourceHide":
+		if message.Counter >= 0 &&
+			message.Counter < len(engine.sources) {
+
+			engine.sources[message.Counter].hidden = true
+		}
+	case "sourceShow":
+		if message.Counter >= 0 &&
+			message.Counter < len(engine.sources) {
+			engine.sources[message.Counter].hidden = false
+		}
+	case "sourceTitle":
+		if message.Counter >= 0 &&
+			message.Counter < len(engine.sources) {
+
+			engine.sources[message.Counter].title = message.Data
+		}
+	case "showInfo":
+		engine.showInfo = true
+		engine.infoTimer.Reset(time.Duration(message.Counter) * time.Second)
+	case "sourceColors":
+		if message.Counter >= 0 &&
+			message.Counter < len(engine.sources) &&
+			len(message.Colors) == 2 {
+
+			log.Printf("Setting source %d colors: %v - %v", message.Counter+1, message.Colors[0], message.Colors[1])
+			engine.setSourceColors(message.Counter, message.Colors[0], message.Colors[1])
+			engine.sources[message.Counter].colorOverride = true
+			engine.sources[message.Counter].cueColors = nil
+		}
+	case "titleColors":
+		if len(message.Colors) == 2 {
+			log.Printf("Setting title colors: %v - %v", message.Colors[0], message.Colors[1])
+			engine.setTitleColors(message.Colors[0], message.Colors[1])
+		}
+	case "screenFlash":
+		engine.screenFlash = true
+		engine.flashTimer.Reset(flashDuration)
+	case "timerSignal":
+		if message.Counter >= 0 &&
+			message.Counter < len(engine.Counters) &&
+			len(message.Colors) == 1 {
+			engine.saveHistory(message.Counter)
+			engine.Counters[message.Counter].signalColor = message.Colors[0]
+		}
+	case "rundownGo":
+		engine.rundownGo()
+	case "rundownNext":
+		engine.rundownNext()
+	case "rundownPrevious":
+		engine.rundownPrevious()
+	case "rundownJump":
+		engine.startSegment(message.Counter)
+	case "rundownAdd":
+		if message.Segment != nil {
+			engine.addSegment(*message.Segment)
+		}
+	case "rundownClear":
+		engine.loadRundown(nil)
+	case "logClear":
+		engine.timingLog = nil
+		log.Printf("Timing log cleared")
+	case "showHardOut":
+		if err := engine.setHardOut(message.Data); err != nil {
+			log.Printf("Error setting show hard out: %v", err)
+		}
+	case "scheduleAdd":
+		if err := engine.addSchedule(message.Data); err != nil {
+			log.Printf("Error adding schedule entry: %v", err)
+		}
+	case "scheduleRemove":
+		if err := engine.removeSchedule(message.Counter); err != nil {
+			log.Printf("Error removing schedule entry: %v", err)
+		}
+	case "scheduleList":
+		engine.sendSchedule()
+	case "timerPreset":
+		if err := engine.loadPreset(message.Counter, message.Data); err != nil {
+			log.Printf("Error loading timer preset: %v", err)
+		}
+	case "presetList":
+		engine.sendPresets()
+	case "groupCommand":
+		if err := engine.groupCommand(message.Data, message.Command); err != nil {
+			log.Printf("Error running group command: %v", err)
+		}
+	case "timerAction":
+		a := &timerAction{
+			counter: message.Counter,
+			command: message.Data,
+		}
+		if message.CountdownMessage != nil {
+			a.threshold = time.Duration(message.CountdownMessage.Seconds) * time.Second
+		}
+		if err := engine.addTimerAction(a); err != nil {
+			log.Printf("Error adding timer action: %v", err)
+		}
+	case "timerActionsClear":
+		engine.clearTimerActions(message.Counter)
+	case "timerRate":
+		rate, err := strconv.ParseFloat(message.Data, 64)
+		if err != nil {
+			log.Printf("Invalid timer rate: %v", err)
+		} else if err := engine.setCounterRate(message.Counter, rate); err != nil {
+			log.Printf("Error setting timer rate: %v", err)
+		}
+	case "timerUndo":
+		if err := engine.undoCounter(message.Counter); err != nil {
+			log.Printf("Error undoing timer operation: %v", err)
+		}
+	case "timerLink":
+		if err := engine.setLink(message.Counter, message.Data); err != nil {
+			log.Printf("Error linking timer: %v", err)
+		}
+	case "timerLap":
+		engine.lapCounter(message.Counter)
+	case "timerThresholds":
+		if err := engine.setThresholds(message.Counter, message.Data); err != nil {
+			log.Printf("Error setting timer thresholds: %v", err)
+		}
+	case "hardwareSignal":
+		if message.Counter == engine.signalHardware && len(message.Colors) == 1 {
+			engine.signalHardwareColor = message.Colors[0]
 		}
 	}
+	// We have received a osc command, so stop the version display
+	engine.initialized = true
 }
 
 // Sends the OSC feedback messages
@@ -567,7 +876,7 @@ func (engine *Engine) sendState(state *State) error {
 		// No osc connection
 		return nil
 	}
-	t := time.Now()
+	t := engine.timeSource.Now()
 	engine.sendLegacyState(state)
 
 	bundle := osc.NewBundle(time.Now())
@@ -575,17 +884,36 @@ func (engine *Engine) sendState(state *State) error {
 	for i, s := range state.Clocks {
 		addr := fmt.Sprintf("/clock/source/%d/state", i+1)
 
-		packet := osc.NewMessage(addr, engine.uuid, s.Hidden, s.Text, s.Compact, s.Icon, float32(s.Progress), s.Expired, s.Paused, s.Label, int32(s.Mode))
+		packet := osc.NewMessage(addr, engine.uuid, s.Hidden, s.Text, s.Compact, s.Icon, float32(s.Progress), s.Expired, s.Paused, s.Label, int32(s.Mode), int32(s.Counter), s.TimeZone, s.Inputs, s.EndTime)
 		bundle.Append(packet)
 	}
 
-	for i, c := range engine.Counters {
+	for i, out := range engine.counterOutputs(t) {
 		addr := fmt.Sprintf("/clock/timer/%d/state", i)
-		out := c.Output(t)
 
-		packet := osc.NewMessage(addr, engine.uuid, out.Active, out.Text, out.Compact, out.Icon, float32(out.Progress), out.Expired, out.Paused)
+		packet := osc.NewMessage(addr, engine.uuid, out.Active, out.Text, out.Compact, out.Icon, float32(out.Progress), out.Expired, out.Paused, float32(out.Rate))
 		bundle.Append(packet)
+
+		if len(out.Laps) > 0 {
+			args := []interface{}{engine.uuid, float32(out.LapTime.Seconds())}
+			for _, lap := range out.Laps {
+				args = append(args, float32(lap.Seconds()))
+			}
+			bundle.Append(osc.NewMessage(fmt.Sprintf("/clock/timer/%d/laps", i), args...))
+		}
+	}
+
+	r := state.Rundown
+	bundle.Append(osc.NewMessage("/clock/rundown/state", engine.uuid, int32(r.Current), int32(r.Segments), r.Title, r.NextTitle))
+	show := state.Show
+	var hardOut, projected string
+	if show.Active {
+		hardOut = show.HardOut.Format("15:04:05")
+		projected = show.Projected.Format("15:04:05")
 	}
+	bundle.Append(osc.NewMessage("/clock/show/state", engine.uuid, show.Active, int32(show.OverUnder.Round(time.Second).Seconds()), hardOut, projected))
+	engine.groupFeedback(bundle)
+	engine.linkFeedback(bundle)
 
 	data, err := bundle.MarshalBinary()
 	if err != nil {
@@ -595,10 +923,27 @@ func (engine *Engine) sendState(state *State) error {
 	return nil
 }
 
+// counterOutputs takes a locked snapshot of all counter outputs
+func (engine *Engine) counterOutputs(t time.Time) []*CounterOutput {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	engine.syncLinks()
+	outputs := make([]*CounterOutput, len(engine.Counters))
+	for i, c := range engine.Counters {
+		outputs[i] = c.Output(t)
+	}
+	return outputs
+}
+
 func (engine *Engine) sendUDPTimers() {
-	t := time.Now()
+	t := engine.timeSource.Now()
 	for i, conn := range engine.udpDests {
+		engine.mutex.Lock()
+		engine.syncLinks()
 		c := engine.udpCounters[i].Output(t)
+		engine.mutex.Unlock()
+
 		mins := c.Minutes
 		secs := c.Seconds
 
@@ -667,7 +1012,14 @@ func (engine *Engine) flash(t time.Time) bool {
 
 // State creates a snapshot of the clock state for display on clock faces
 func (engine *Engine) State() *State {
-	t := time.Now()
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.state()
+}
+
+func (engine *Engine) state() *State {
+	t := engine.timeSource.Now()
+	engine.syncLinks()
 	var clocks []*Clock
 	for _, s := range engine.sources {
 		c := Clock{
@@ -679,22 +1031,23 @@ func (engine *Engine) State() *State {
 			Hidden:      s.hidden,
 			TextColor:   s.textColor,
 			BGColor:     s.bgColor,
-			HideSeconds: !engine.displaySeconds,
+			HideSeconds: !s.displaySeconds,
 			SignalColor: color.RGBA{R: 0, G: 0, B: 0, A: 0},
+			Counter:     s.counterNumber,
+			TimeZone:    s.tz.String(),
+			Inputs:      s.inputSpec,
 		}
-
-		if s.timer {
-			c.SignalColor = s.counter.signalColor
+		if len(s.cueColors) == 2 {
+			c.TextColor = s.cueColors[0]
+			c.BGColor = s.cueColors[1]
 		}
 
-		if s.ltc && engine.ltcActive {
-			engine.ltcState(&c, s)
-		} else if s.timer && s.counter.active {
-			engine.timerState(&c, s, t)
-		} else if s.tod {
-			engine.todState(&c, s, t)
+		if counter := engine.signalCounter(s); counter != nil {
+			c.SignalColor = counter.signalColor
 		}
 
+		engine.sourceState(&c, s, t)
+
 		clocks = append(clocks, &c)
 	}
 	state := State{
@@ -707,6 +1060,8 @@ func (engine *Engine) State() *State {
 		TitleBGColor:        engine.titleBGColor,
 		ScreenFlash:         engine.screenFlash,
 		HardwareSignalColor: engine.signalHardwareColor,
+		Rundown:             engine.rundownState(),
+		Show:                engine.showState(t),
 	}
 
 	if engine.showInfo {
@@ -723,23 +1078,24 @@ func (engine *Engine) State() *State {
 	return &state
 }
 
-func (engine *Engine) todState(c *Clock, s *source, t time.Time) {
+func (engine *Engine) todState(c *Clock, s *source, tz *time.Location, t time.Time) {
 	// Time of day
 	c.Mode = Normal
-	if engine.format12h {
-		c.Text = t.In(s.tz).Format("03:04:05")
-		c.Hours = t.In(s.tz).Hour() % 12
+	if s.format12h {
+		c.Text = t.In(tz).Format("03:04:05")
+		c.Hours = t.In(tz).Hour() % 12
 	} else {
-		c.Text = t.In(s.tz).Format("15:04:05")
-		c.Hours = t.In(s.tz).Hour()
+		c.Text = t.In(tz).Format("15:04:05")
+		c.Hours = t.In(tz).Hour()
 	}
-	c.Minutes = t.In(s.tz).Minute()
-	c.Seconds = t.In(s.tz).Second()
+	c.Minutes = t.In(tz).Minute()
+	c.Seconds = t.In(tz).Second()
 
 	// Hide seconds if requested
-	if !engine.displaySeconds {
+	if !s.displaySeconds {
 		c.Text = c.Text[0:5]
 	}
+	applyFormat(c, s.format, t.In(tz))
 }
 
 func (engine *Engine) ltcState(c *Clock, s *source) {
@@ -757,7 +1113,7 @@ func (engine *Engine) ltcState(c *Clock, s *source) {
 	} else if engine.ltcFollow {
 		// Follow the LTC time when signal is lost
 		// Todo: must be easier way to print out the duration...
-		t := time.Now()
+		t := engine.timeSource.Now()
 		diff := t.Sub(engine.ltc.target)
 		c.Text = fmt.Sprintf("%s:%02d", formatDuration(diff), 0)
 		c.Hours, c.Minutes, c.Seconds = splatDuration(diff)
@@ -767,10 +1123,11 @@ func (engine *Engine) ltcState(c *Clock, s *source) {
 	}
 }
 
-func (engine *Engine) timerState(c *Clock, s *source, t time.Time) {
+func (engine *Engine) timerState(c *Clock, s *source, counter *Counter, t time.Time) {
 	// Active timer
-	out := s.counter.Output(t)
+	out := counter.Output(t)
 	c.Text = out.Text
+	c.Days = out.Days
 	c.Hours = out.Hours
 	c.Minutes = out.Minutes
 	c.Seconds = out.Seconds
@@ -780,36 +1137,44 @@ func (engine *Engine) timerState(c *Clock, s *source, t time.Time) {
 	c.Progress = out.Progress
 	c.Icon = out.Icon
 	c.HideHours = out.HideHours
+	if len(out.Laps) > 0 {
+		c.Lap = len(out.Laps) + 1
+		c.LapTime = out.LapTime
+		c.LastLap = out.Laps[len(out.Laps)-1]
+	}
 
-	if engine.autoSignals {
+	if counter.thresholds != nil {
+		engine.thresholdSignal(counter, out, t)
+		c.SignalColor = counter.signalColor
+	} else if engine.autoSignals {
 		if out.Countdown {
 			if out.Diff < engine.signalThresholdEnd {
-				s.counter.setAutoColor(engine.signalColors[colorEnd], autoColorEnd)
+				counter.setAutoColor(engine.signalColors[colorEnd], autoColorEnd)
 			} else if out.Diff < engine.signalThresholdWarning {
-				s.counter.setAutoColor(engine.signalColors[colorWarning], autoColorWarn)
+				counter.setAutoColor(engine.signalColors[colorWarning], autoColorWarn)
 			} else if engine.signalStart {
-				s.counter.setAutoColor(engine.signalColors[colorStart], autoColorStart)
+				counter.setAutoColor(engine.signalColors[colorStart], autoColorStart)
 			} else {
-				s.counter.setAutoColor(color.RGBA{R: 0, G: 0, B: 0, A: 0}, autoColorOff)
+				counter.setAutoColor(color.RGBA{R: 0, G: 0, B: 0, A: 0}, autoColorOff)
 			}
 		} else if engine.signalStart {
-			s.counter.setAutoColor(engine.signalColors[colorStart], autoColorStart)
+			counter.setAutoColor(engine.signalColors[colorStart], autoColorStart)
 		} else {
-			s.counter.setAutoColor(color.RGBA{R: 0, G: 0, B: 0, A: 0}, autoColorOff)
+			counter.setAutoColor(color.RGBA{R: 0, G: 0, B: 0, A: 0}, autoColorOff)
 		}
-		c.SignalColor = s.counter.signalColor
+		c.SignalColor = counter.signalColor
 	} else {
 		c.SignalColor = out.SignalColor
 	}
 
-	if s.counter.slave != nil {
+	if counter.slave != nil {
 		c.Mode = Slave
-	} else if s.counter.media != nil {
+	} else if counter.media != nil {
 		c.Mode = Media
 	} else if out.Countdown {
 		c.Mode = Countdown
 		if out.Expired {
-			switch engine.overtimeCountMode {
+			switch s.overtimeCountMode {
 			case "zero":
 				// Default, nothing to do
 			case "blank":
@@ -819,7 +1184,7 @@ func (engine *Engine) timerState(c *Clock, s *source, t time.Time) {
 				overtimeFormat(out, c)
 				c.Text = fmt.Sprintf("%02d:%02d:%02d", c.Hours, c.Minutes, c.Seconds)
 			}
-			switch engine.overtimeVisibility {
+			switch s.overtimeVisibility {
 			case "none":
 				c.Expired = false
 			case "blink":
@@ -833,6 +1198,30 @@ func (engine *Engine) timerState(c *Clock, s *source, t time.Time) {
 	} else {
 		c.Mode = Countup
 	}
+
+	// Tenths or hundredths of seconds on the final stretch, including the last second before expiry
+	if s.subSeconds > 0 && (c.Mode == Countdown || c.Mode == Countup) &&
+		out.Diff > 0 && out.Diff < s.subSeconds && out.Hours == 0 {
+		c.SubSeconds = true
+		c.Hundredths = s.hundredths
+		c.Fraction = out.Fraction
+	}
+
+	// Back-timing, paused countdowns keep moving the end time
+	if s.backTime && c.Mode == Countdown && !out.Expired {
+		end := t.Add(counter.unscale(out.Diff)).In(s.tz)
+		if s.format12h {
+			c.EndTime = end.Format("03:04:05 PM")
+		} else {
+			c.EndTime = end.Format("15:04:05")
+		}
+	}
+
+	format := s.format
+	if c.Mode == Countdown && out.Expired && s.overtimeFormat != "" {
+		format = s.overtimeFormat
+	}
+	applyFormat(c, format, t.In(s.tz))
 }
 
 /*
@@ -841,29 +1230,63 @@ func (engine *Engine) timerState(c *Clock, s *source, t time.Time) {
 
 // StartCounter starts a counter
 func (engine *Engine) StartCounter(counter int, countdown bool, timer time.Duration) {
-	if counter < 0 || counter >= numCounters {
-		log.Printf("engine.StartCounter: illegal counter number %d (have %d counters)\n", counter, numCounters)
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.startCounter(counter, countdown, timer)
+}
+
+func (engine *Engine) startCounter(counter int, countdown bool, timer time.Duration) {
+	if counter < 0 || counter >= len(engine.Counters) {
+		log.Printf("engine.StartCounter: illegal counter number %d (have %d counters)\n", counter, len(engine.Counters))
+		return
 	}
 
+	engine.saveHistory(counter)
 	engine.Counters[counter].Start(countdown, timer)
 	engine.activateSourceByCounter(counter)
+	engine.publishEvent(EventStart, counter)
 }
 
 // ModifyCounter adds or removes time from a counter
 func (engine *Engine) ModifyCounter(counter int, delta time.Duration) {
-	if counter < 0 || counter >= numCounters {
-		log.Printf("engine.StartCounter: illegal counter number %d (have %d counters)\n", counter, numCounters)
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.modifyCounter(counter, delta)
+}
+
+func (engine *Engine) modifyCounter(counter int, delta time.Duration) {
+	if counter < 0 || counter >= len(engine.Counters) {
+		log.Printf("engine.ModifyCounter: illegal counter number %d (have %d counters)\n", counter, len(engine.Counters))
+		return
 	}
 
+	if !engine.Counters[counter].active {
+		return
+	}
+	engine.saveHistory(counter)
 	engine.Counters[counter].Modify(delta)
+	e := engine.counterEvent(EventModify, counter)
+	e.Delta = int(delta.Seconds())
+	engine.publish(e)
 }
 
 // StopCounter stops a given counter
 func (engine *Engine) StopCounter(counter int) {
-	if counter < 0 || counter >= numCounters {
-		log.Printf("engine.StartCounter: illegal counter number %d (have %d counters)\n", counter, numCounters)
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.stopCounter(counter)
+}
+
+func (engine *Engine) stopCounter(counter int) {
+	if counter < 0 || counter >= len(engine.Counters) {
+		log.Printf("engine.StopCounter: illegal counter number %d (have %d counters)\n", counter, len(engine.Counters))
+		return
 	}
 
+	if engine.Counters[counter].active {
+		engine.saveHistory(counter)
+		engine.publishEvent(EventStop, counter)
+	}
 	engine.Counters[counter].Stop()
 	if engine.autoSignals {
 		engine.Counters[counter].signalColor = color.RGBA{R: 0, G: 0, B: 0, A: 0}
@@ -872,57 +1295,122 @@ func (engine *Engine) StopCounter(counter int) {
 
 // PauseCounter pauses a given counter
 func (engine *Engine) PauseCounter(counter int) {
-	if counter < 0 || counter >= numCounters {
-		log.Printf("engine.StartCounter: illegal counter number %d (have %d counters)\n", counter, numCounters)
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.pauseCounter(counter)
+}
+
+// LapCounter records a stopwatch lap on a count up
+func (engine *Engine) LapCounter(counter int) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.lapCounter(counter)
+}
+
+func (engine *Engine) lapCounter(counter int) {
+	if counter < 0 || counter >= len(engine.Counters) {
+		log.Printf("engine.LapCounter: illegal counter number %d (have %d counters)\n", counter, len(engine.Counters))
+		return
+	}
+	if err := engine.Counters[counter].Lap(engine.timeSource.Now()); err != nil {
+		log.Printf("engine.LapCounter: counter %d: %v", counter, err)
+	}
+}
+
+// SetCounterRate sets the counting speed of a counter relative to real time
+func (engine *Engine) SetCounterRate(counter int, rate float64) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.setCounterRate(counter, rate)
+}
+
+func (engine *Engine) setCounterRate(counter int, rate float64) error {
+	if counter < 0 || counter >= len(engine.Counters) {
+		return fmt.Errorf("counter number %d out of range (have %d counters)", counter, len(engine.Counters))
+	}
+	if rate <= 0 || rate > maxRate {
+		return fmt.Errorf("rate %v out of range (0-%v)", rate, maxRate)
+	}
+	log.Printf("Setting counter %d rate to %v", counter, rate)
+	engine.saveHistory(counter)
+	return engine.Counters[counter].SetRate(rate)
+}
+
+func (engine *Engine) pauseCounter(counter int) {
+	if counter < 0 || counter >= len(engine.Counters) {
+		log.Printf("engine.PauseCounter: illegal counter number %d (have %d counters)\n", counter, len(engine.Counters))
+		return
+	}
+	engine.pauseCounterEvent(counter)
+}
+
+// pauseCounterEvent pauses a counter and publishes the event if it was running
+func (engine *Engine) pauseCounterEvent(counter int) {
+	c := engine.Counters[counter]
+	running := c.active && !c.paused
+	if running {
+		engine.saveHistory(counter)
+	}
+	c.Pause()
+	if running {
+		engine.publishEvent(EventPause, counter)
 	}
-	engine.Counters[counter].Pause()
 }
 
 // ResumeCounter resumes a paused counter
 func (engine *Engine) ResumeCounter(counter int) {
-	if counter < 0 || counter >= numCounters {
-		log.Printf("engine.StartCounter: illegal counter number %d (have %d counters)\n", counter, numCounters)
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.resumeCounter(counter)
+}
+
+func (engine *Engine) resumeCounter(counter int) {
+	if counter < 0 || counter >= len(engine.Counters) {
+		log.Printf("engine.ResumeCounter: illegal counter number %d (have %d counters)\n", counter, len(engine.Counters))
+		return
+	}
+	engine.resumeCounterEvent(counter)
+}
+
+// resumeCounterEvent resumes a counter and publishes the event if it was paused
+func (engine *Engine) resumeCounterEvent(counter int) {
+	c := engine.Counters[counter]
+	paused := c.active && c.paused
+	if paused {
+		engine.saveHistory(counter)
+	}
+	c.Resume()
+	if paused {
+		engine.publishEvent(EventResume, counter)
 	}
-	engine.Counters[counter].Resume()
 }
 
-// TargetCounter sets the target time and date for a counter
+// TargetCounter sets the target time and date for a counter. The target is
+// a time of day as HH:MM:SS or a ISO 8601 date-time, optionally followed by a time zone name.
 func (engine *Engine) TargetCounter(counter int, target string, countdown bool) {
-	if counter < 0 || counter >= numCounters {
-		log.Printf("engine.TargetCounter: illegal counter number %d (have %d counters)\n", counter, numCounters)
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.targetCounter(counter, target, countdown)
+}
+
+func (engine *Engine) targetCounter(counter int, target string, countdown bool) {
+	if counter < 0 || counter >= len(engine.Counters) {
+		log.Printf("engine.TargetCounter: illegal counter number %d (have %d counters)\n", counter, len(engine.Counters))
+		return
 	}
 
-	match, err := regexp.MatchString("^([0-1]?[0-9]|2[0-3]):([0-5][0-9]):([0-5][0-9])$", target)
-	if match && err == nil {
-		tz := engine.sources[0].tz
-		now := time.Now().In(tz)
-		t, err := time.ParseInLocation("15:04:05", target, tz)
-		if err != nil {
-			log.Printf("TargetCounter error: %v", err)
-			return
-		}
-		target := time.Date(
-			now.Year(),
-			now.Month(),
-			now.Day(),
-			t.Hour(),
-			t.Minute(),
-			t.Second(),
-			0,
-			tz)
-
-		if target.Before(now) && countdown {
-			target = target.Add(24 * time.Hour)
-		} else if target.After(now) && !countdown {
-			target = target.Add(-24 * time.Hour)
-		}
-
-		engine.Counters[counter].Target(target)
-		engine.activateSourceByCounter(counter)
-		debug.Printf("Counter target set!")
-	} else {
+	now := engine.timeSource.Now()
+	t, err := parseTarget(target, now, engine.sources[0].tz, countdown)
+	if err != nil {
 		log.Printf("Illegal timer target string, string: %v, err: %v", target, err)
+		return
 	}
+
+	engine.saveHistory(counter)
+	engine.Counters[counter].Start(countdown, t.Sub(now))
+	engine.activateSourceByCounter(counter)
+	engine.publishEvent(EventStart, counter)
+	debug.Printf("Counter target set: %v", t)
 }
 
 // showAll returns main display to normal clock
@@ -941,15 +1429,27 @@ func (engine *Engine) hideAll() {
 
 // Pause pauses all timers
 func (engine *Engine) Pause() {
-	for _, c := range engine.Counters {
-		c.Pause()
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.pause()
+}
+
+func (engine *Engine) pause() {
+	for i := range engine.Counters {
+		engine.pauseCounterEvent(i)
 	}
 }
 
 // Resume resumes all timers
 func (engine *Engine) Resume() {
-	for _, c := range engine.Counters {
-		c.Resume()
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.resume()
+}
+
+func (engine *Engine) resume() {
+	for i := range engine.Counters {
+		engine.resumeCounterEvent(i)
 	}
 }
 
@@ -957,6 +1457,8 @@ func (engine *Engine) Resume() {
 
 // DisplaySeconds returns true if the clock should display seconds
 func (engine *Engine) DisplaySeconds() bool {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
 	return engine.displaySeconds
 }
 
@@ -988,6 +1490,8 @@ func (engine *Engine) setTime(time string) {
 
 // LtcActive returns true if the clock is displaying LTC timecode
 func (engine *Engine) LtcActive() bool {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
 	return engine.mode == LTC
 }
 
@@ -1005,7 +1509,7 @@ func (engine *Engine) setLTC(timestamp string) {
 			ltcDuration := time.Duration(hours) * time.Hour
 			ltcDuration += time.Duration(minutes) * time.Minute
 			ltcDuration += time.Duration(seconds) * time.Second
-			ltcTarget = time.Now().Add(-ltcDuration)
+			ltcTarget = engine.timeSource.Now().Add(-ltcDuration)
 		} else {
 			ltcTarget = engine.ltc.target
 		}
@@ -1040,21 +1544,28 @@ func (engine *Engine) printVersion() {
 }
 
 // initCounters initializes the countdown and count up timers
-func (engine *Engine) initCounters() {
-	engine.Counters = make([]*Counter, numCounters)
-	for i := 0; i < numCounters; i++ {
+func (engine *Engine) initCounters(count int) {
+	engine.Counters = make([]*Counter, count)
+	for i := 0; i < count; i++ {
 		engine.Counters[i] = &Counter{
-			active: false,
-			state:  &counterState{},
+			active:     false,
+			state:      &counterState{},
+			timeSource: engine.timeSource,
 		}
 	}
+	engine.eventStates = make([]eventState, count)
+	engine.history = make([][]counterSnapshot, count)
+	engine.defaultThresholds = make([]thresholdProfile, count)
 	log.Printf("Initialized %d timer counters", len(engine.Counters))
 }
 
 func (engine *Engine) initSources(sources []*SourceOptions) error {
-	// Todo get the map from options...
 	engine.sources = make([]*source, len(sources))
 	for i, s := range sources {
+		if s.Counter < 0 || s.Counter >= len(engine.Counters) {
+			return fmt.Errorf("source %d: counter number %d out of range (have %d counters)", i+1, s.Counter, len(engine.Counters))
+		}
+
 		// Time zone
 		tz, err := time.LoadLocation(s.TimeZone)
 		if err != nil {
@@ -1067,15 +1578,70 @@ func (engine *Engine) initSources(sources []*SourceOptions) error {
 			return err
 		}
 
+		// Input list, the legacy flags are used if not set
+		spec := s.Inputs
+		if strings.TrimSpace(spec) == "" {
+			spec = defaultInputs(s.LTC, s.Timer, s.Tod)
+		}
+		inputs, err := parseInputs(spec, len(engine.Counters))
+		if err != nil {
+			return fmt.Errorf("source %d inputs: %v", i+1, err)
+		}
+
+		// Overtime settings default to the global ones
+		countMode := engine.overtimeCountMode
+		if s.OvertimeCountMode != "" {
+			countMode = s.OvertimeCountMode
+		}
+		if err := validateOvertimeMode(countMode); err != nil {
+			return fmt.Errorf("source %d: %v", i+1, err)
+		}
+		visibility := engine.overtimeVisibility
+		if s.OvertimeVisibility != "" {
+			visibility = s.OvertimeVisibility
+		}
+		if err := validateOvertimeVisibility(visibility); err != nil {
+			return fmt.Errorf("source %d: %v", i+1, err)
+		}
+		format12h := engine.format12h
+		if s.TimeFormat != "" {
+			if err := validateTimeFormat(s.TimeFormat); err != nil {
+				return fmt.Errorf("source %d: %v", i+1, err)
+			}
+			format12h = s.TimeFormat == "12h"
+		}
+		if s.SubSeconds < 0 {
+			return fmt.Errorf("source %d: negative sub-second threshold %d", i+1, s.SubSeconds)
+		}
+		if err := ValidateFormat(s.Format); err != nil {
+			return fmt.Errorf("source %d: %v", i+1, err)
+		}
+		if err := ValidateFormat(s.OvertimeFormat); err != nil {
+			return fmt.Errorf("source %d overtime: %v", i+1, err)
+		}
+
 		engine.sources[i] = &source{
-			counter:  engine.Counters[s.Counter],
-			tod:      s.Tod,
-			timer:    s.Timer,
-			ltc:      s.LTC,
-			tz:       tz,
-			title:    s.Text,
-			hidden:   s.Hidden,
-			overtime: c,
+			counter:       engine.Counters[s.Counter],
+			counterNumber: s.Counter,
+			inputs:        inputs,
+			inputSpec:     spec,
+			tz:            tz,
+			title:         s.Text,
+			hidden:        s.Hidden,
+			overtime:      c,
+
+			overtimeCountMode:  countMode,
+			overtimeVisibility: visibility,
+			format12h:          format12h,
+			displaySeconds:     !s.HideSeconds,
+			subSeconds:         time.Duration(s.SubSeconds) * time.Second,
+			hundredths:         s.Hundredths,
+			format:             s.Format,
+			overtimeFormat:     s.OvertimeFormat,
+			backTime:           s.BackTime,
+
+			defaultTitle:  s.Text,
+			defaultHidden: s.Hidden,
 		}
 	}
 	log.Printf("Initialized %d clock display sources", len(engine.sources))
@@ -1089,12 +1655,6 @@ func (engine *Engine) initOSC(options *EngineOptions) {
 			Addr: options.ListenAddr,
 		}
 		engine.clockServer = MakeServer(&engine.oscServer, engine.uuid)
-		log.Printf("OSC control: listening on %v", engine.oscServer.Addr)
-
-		go engine.runOSC()
-
-		// process osc commands
-		go engine.listen()
 
 		if options.DisableFeedback {
 			engine.oscDests = nil
@@ -1107,6 +1667,21 @@ func (engine *Engine) initOSC(options *EngineOptions) {
 		log.Printf("OSC control and feedback disabled.\n")
 	}
 	engine.oscSendChan = make(chan []byte)
+}
+
+// startOSC starts the OSC listener and the command processing loop. It must be
+// called after all engine fields have been initialized.
+func (engine *Engine) startOSC(options *EngineOptions) {
+	if !options.DisableOSC {
+		log.Printf("OSC control: listening on %v", engine.oscServer.Addr)
+
+		go engine.runOSC()
+
+		// process osc commands
+		go engine.listen()
+	} else {
+		log.Printf("OSC control: disabled, schedule and timer actions still run")
+	}
 	go engine.oscSender()
 }
 
@@ -1126,14 +1701,54 @@ func (engine *Engine) activateSourceByCounter(c int) {
 	}
 }
 
-// SetSourceColors sets the source output colors
+// SetSourceColors sets the default source output colors. Colors set over OSC take precedence,
+// unless they were restored from the state file and the configured colors have changed since.
 func (engine *Engine) SetSourceColors(source int, text, bg color.RGBA) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	if source < 0 || source >= len(engine.sources) {
+		log.Printf("engine.SetSourceColors: illegal source number %d (have %d sources)\n", source, len(engine.sources))
+		return
+	}
+	s := engine.sources[source]
+	if s.colorOverride && (len(s.defaultColors) != 2 || s.defaultColors[0] != text || s.defaultColors[1] != bg) {
+		log.Printf("Source %d colors changed in the configuration, dropping the colors set over OSC", source+1)
+		s.colorOverride = false
+	}
+	s.defaultColors = []color.RGBA{text, bg}
+	if s.colorOverride {
+		return
+	}
+	engine.setSourceColors(source, text, bg)
+}
+
+func (engine *Engine) setSourceColors(source int, text, bg color.RGBA) {
 	engine.sources[source].textColor = text
 	engine.sources[source].bgColor = bg
 }
 
+// resetSourceColors clears the colors set over OSC and returns to the configured ones
+func (engine *Engine) resetSourceColors(source int) error {
+	s, err := engine.getSource(source)
+	if err != nil {
+		return err
+	}
+	s.colorOverride = false
+	s.cueColors = nil
+	if len(s.defaultColors) == 2 {
+		engine.setSourceColors(source, s.defaultColors[0], s.defaultColors[1])
+	}
+	return nil
+}
+
 // SetTitleColors sets the source title colors
 func (engine *Engine) SetTitleColors(text, bg color.RGBA) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.setTitleColors(text, bg)
+}
+
+func (engine *Engine) setTitleColors(text, bg color.RGBA) {
 	engine.titleTextColor = text
 	engine.titleBGColor = bg
 }
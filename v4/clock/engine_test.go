@@ -0,0 +1,272 @@
+package clock
+
+import (
+	"github.com/stanchan/go-osc/osc"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testStart is the fake time the test engines start at
+var testStart = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestEngine creates an engine on a fake clock without network connections.
+// The options can be adjusted before the engine is created.
+func newTestEngine(t *testing.T, adjust func(*EngineOptions)) (*Engine, *FakeTime) {
+	t.Helper()
+	fake := NewFakeTime(testStart)
+	options := &EngineOptions{
+		DisableOSC:             true,
+		DisableFeedback:        true,
+		ListenAddr:             "127.0.0.1:0",
+		Connect:                "127.0.0.1:1245",
+		UDPTime:                "off",
+		Flash:                  500,
+		Timeout:                1000,
+		ShowInfo:               1,
+		Ignore:                 "ignore",
+		SignalColorStart:       "#00FF00",
+		SignalColorWarning:     "#FFFF00",
+		SignalColorEnd:         "#FF0000",
+		SignalThresholdWarning: 120,
+		SignalThresholdEnd:     60,
+		OvertimeCountMode:      "zero",
+		OvertimeVisibility:     "blink",
+		Counters:               4,
+		TimeSource:             fake,
+		Sources: []*SourceOptions{
+			{Counter: 1, Timer: true, Tod: true, TimeZone: "UTC", OvertimeColor: "#FF0000"},
+			{Counter: 2, Timer: true, Tod: true, TimeZone: "UTC", OvertimeColor: "#FF0000"},
+		},
+	}
+	if adjust != nil {
+		adjust(options)
+	}
+	engine, err := MakeEngine(options)
+	if err != nil {
+		t.Fatalf("MakeEngine: %v", err)
+	}
+	return engine, fake
+}
+
+// TestConcurrentAccess drives OSC commands, the exported engine API and State()
+// from separate goroutines while the engine loop runs. Run with -race.
+func TestConcurrentAccess(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.DisableOSC = false
+		o.DisableFeedback = false
+		o.Groups = []string{"stage 1,2"}
+	})
+	server := engine.clockServer
+
+	commands := []func(){
+		func() { server.handleCountdownStart(osc.NewMessage("/clock/timer/1/countdown", int32(600))) },
+		func() { server.handleTimerPause(osc.NewMessage("/clock/timer/1/pause")) },
+		func() { server.handleTimerResume(osc.NewMessage("/clock/timer/1/resume")) },
+		func() { server.handleTimerModify(osc.NewMessage("/clock/timer/1/modify", int32(60))) },
+		func() { server.handleSourceTitle(osc.NewMessage("/clock/source/1/title", "Keynote")) },
+		func() { server.handleHideAll(osc.NewMessage("/clock/hide")) },
+		func() { server.handleShowAll(osc.NewMessage("/clock/show")) },
+		func() { server.handleTimerStop(osc.NewMessage("/clock/timer/1/stop")) },
+		func() { server.handleTimerLap(osc.NewMessage("/clock/timer/1/lap")) },
+		func() { server.handleTimerLink(osc.NewMessage("/clock/timer/2/link", int32(1), "-5:00")) },
+		func() { server.handleGroupCommand(osc.NewMessage("/clock/group/stage/countdown", int32(300))) },
+	}
+
+	deadline := time.Now().Add(1200 * time.Millisecond) // Long enough for the state feedback ticker
+	var wg sync.WaitGroup
+	run := func(f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; time.Now().Before(deadline); i++ {
+				f(i)
+			}
+		}()
+	}
+
+	run(func(i int) {
+		commands[i%len(commands)]()
+	})
+	run(func(i int) {
+		engine.StartCounter(2, i%2 == 0, 10*time.Minute)
+		engine.ModifyCounter(2, time.Minute)
+		fake.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+	})
+	run(func(i int) {
+		state := engine.State()
+		for _, c := range state.Clocks {
+			_ = c.Text
+		}
+		if err := engine.sendState(state); err != nil {
+			t.Errorf("sendState: %v", err)
+		}
+	})
+	wg.Wait()
+
+	if n := len(engine.State().Clocks); n != 2 {
+		t.Errorf("expected 2 clocks in state, got %d", n)
+	}
+}
+
+func TestOvertimeModes(t *testing.T) {
+	overtime := color.RGBA{R: 255, A: 255}
+	tests := []struct {
+		countMode  string
+		visibility string
+		text       string
+		expired    bool
+		background bool // Overtime background color
+	}{
+		{"zero", "blink", "00:00:00", true, false},
+		{"blank", "blink", "", true, false},
+		{"continue", "blink", "00:00:31", true, false}, // The countdown shows zero for its last second
+		{"zero", "none", "00:00:00", false, false},
+		{"zero", "background", "00:00:00", false, true},
+		{"continue", "both", "00:00:31", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.countMode+"/"+tt.visibility, func(t *testing.T) {
+			engine, fake := newTestEngine(t, func(o *EngineOptions) {
+				o.OvertimeCountMode = tt.countMode
+				o.OvertimeVisibility = tt.visibility
+			})
+			engine.StartCounter(1, true, time.Minute)
+			fake.Advance(90 * time.Second)
+
+			c := engine.State().Clocks[0]
+			if c.Mode != Countdown {
+				t.Fatalf("mode = %d, want countdown", c.Mode)
+			}
+			if c.Text != tt.text {
+				t.Errorf("text = %q, want %q", c.Text, tt.text)
+			}
+			if c.Expired != tt.expired {
+				t.Errorf("expired = %v, want %v", c.Expired, tt.expired)
+			}
+			if (c.BGColor == overtime) != tt.background {
+				t.Errorf("background = %v, overtime background wanted: %v", c.BGColor, tt.background)
+			}
+		})
+	}
+}
+
+func TestSourceTimeFormat(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.Format12h = true
+		o.Sources[0].TimeFormat = "24h"
+	})
+	fake.Set(time.Date(2021, 3, 1, 15, 4, 5, 0, time.UTC))
+
+	clocks := engine.State().Clocks
+	if clocks[0].Text != "15:04:05" {
+		t.Errorf("source 1 with 24h format = %q, want 15:04:05", clocks[0].Text)
+	}
+	if clocks[1].Text != "03:04:05" {
+		t.Errorf("source 2 with the global 12h format = %q, want 03:04:05", clocks[1].Text)
+	}
+
+	engine.mutex.Lock()
+	engine.handleMessage(Message{Type: "sourceFormat24h", Counter: 1})
+	engine.mutex.Unlock()
+	if text := engine.State().Clocks[1].Text; text != "15:04:05" {
+		t.Errorf("source 2 after /format/24h = %q, want 15:04:05", text)
+	}
+
+	if err := validateTimeFormat("13h"); err == nil {
+		t.Errorf("invalid time format accepted")
+	}
+}
+
+func TestBackTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		run    func(e *Engine, fake *FakeTime)
+		want   string
+	}{
+		{"running", "24h", func(e *Engine, fake *FakeTime) {
+			e.StartCounter(1, true, 90*time.Minute)
+			fake.Advance(10 * time.Minute)
+		}, "13:30:00"},
+		{"12 hour format", "12h", func(e *Engine, fake *FakeTime) {
+			e.StartCounter(1, true, 90*time.Minute)
+		}, "01:30:00 PM"},
+		{"paused countdown moves the end", "24h", func(e *Engine, fake *FakeTime) {
+			e.StartCounter(1, true, 90*time.Minute)
+			e.PauseCounter(1)
+			fake.Advance(10 * time.Minute)
+		}, "13:40:00"},
+		{"rate adjusted", "24h", func(e *Engine, fake *FakeTime) {
+			e.StartCounter(1, true, 90*time.Minute)
+			e.SetCounterRate(1, 2)
+		}, "12:45:00"},
+		{"expired", "24h", func(e *Engine, fake *FakeTime) {
+			e.StartCounter(1, true, time.Minute)
+			fake.Advance(2 * time.Minute)
+		}, ""},
+		{"count up", "24h", func(e *Engine, fake *FakeTime) {
+			e.StartCounter(1, false, 0)
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, fake := newTestEngine(t, func(o *EngineOptions) {
+				o.Sources[0].BackTime = true
+				o.Sources[0].TimeFormat = tt.format
+			})
+			tt.run(engine, fake)
+			if end := engine.State().Clocks[0].EndTime; end != tt.want {
+				t.Errorf("end time = %q, want %q", end, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceCounterNumber(t *testing.T) {
+	engine, _ := newTestEngine(t, nil)
+	for i, c := range engine.State().Clocks {
+		if want := i + 1; c.Counter != want {
+			t.Errorf("source %d counter = %d, want %d", i+1, c.Counter, want)
+		}
+	}
+
+	engine.mutex.Lock()
+	err := engine.setSourceCounter(0, 3)
+	engine.mutex.Unlock()
+	if err != nil {
+		t.Fatalf("setSourceCounter: %v", err)
+	}
+	if c := engine.State().Clocks[0].Counter; c != 3 {
+		t.Errorf("source 1 counter after change = %d, want 3", c)
+	}
+}
+
+// TestHandlerPatterns checks that each OSC address runs a single handler
+func TestHandlerPatterns(t *testing.T) {
+	engine, _ := newTestEngine(t, func(o *EngineOptions) {
+		o.DisableOSC = false
+	})
+	for _, addr := range []string{
+		"/clock/show",
+		"/clock/hide",
+		"/clock/show/hardout",
+		"/clock/source/1/colors",
+		"/clock/source/1/colors/reset",
+		"/clock/timer/1/countdown",
+		"/clock/timer/1/undo",
+	} {
+		n := 0
+		for _, h := range engine.clockServer.handlers {
+			if h.pattern.MatchString(addr) {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("%s matches %d handlers, want 1", addr, n)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package clock
+
+import (
+	"github.com/stanchan/clock-8001/v4/debug"
+	"log"
+	"sync"
+	"time"
+)
+
+/*
+ * Event bus for counter state transitions
+ */
+
+// Counter event types
+const (
+	EventStart   = "start"   // Counter started or targeted
+	EventStop    = "stop"    // Counter stopped
+	EventPause   = "pause"   // Counter paused
+	EventResume  = "resume"  // Counter resumed
+	EventModify  = "modify"  // Time added to or removed from a counter
+	EventWarning = "warning" // Countdown crossed the warning signal threshold
+	EventEnd     = "end"     // Countdown crossed the end signal threshold
+	EventExpire  = "expire"  // Countdown reached zero
+)
+
+// eventBuffer is the number of events queued for each subscriber before new events are dropped
+const eventBuffer = 64
+
+// Event is a counter state transition published on the EventBus
+type Event struct {
+	Clock     string    `json:"clock"`             // Clock unique id
+	Type      string    `json:"event"`             // One of the Event* constants
+	Counter   int       `json:"counter"`           // Counter number
+	Time      time.Time `json:"time"`              // Time of the transition
+	Countdown bool      `json:"countdown"`         // Is the counter counting down
+	Seconds   int       `json:"seconds"`           // Seconds left on countdowns, elapsed on count ups
+	Text      string    `json:"text"`              // Counter output, generally HH:MM:SS
+	Duration  int       `json:"duration"`          // Total duration of countdowns in seconds
+	Delta     int       `json:"delta,omitempty"`   // Seconds added by a modify event
+	Title     string    `json:"title,omitempty"`   // Title of the rundown segment or the first source showing the counter
+	Segment   int       `json:"segment,omitempty"` // Rundown segment running on the counter, starting from 1
+}
+
+// EventBus distributes counter events to its subscribers
+type EventBus struct {
+	mutex       sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// eventState tracks the threshold crossings of a single countdown
+type eventState struct {
+	warning bool // Above the warning threshold
+	end     bool // Above the end threshold
+	expire  bool // Not yet expired
+}
+
+// Subscribe adds a new subscriber for the events. Events are dropped
+// if the subscriber falls too far behind.
+func (bus *EventBus) Subscribe() chan Event {
+	ch := make(chan Event, eventBuffer)
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	if bus.subscribers == nil {
+		bus.subscribers = make(map[chan Event]struct{})
+	}
+	bus.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (bus *EventBus) Unsubscribe(ch chan Event) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	if _, ok := bus.subscribers[ch]; ok {
+		delete(bus.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (bus *EventBus) publish(e Event) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	for ch := range bus.subscribers {
+		select {
+		case ch <- e:
+		default:
+			log.Printf("Event subscriber queue full, dropping %s event for counter %d", e.Type, e.Counter)
+		}
+	}
+}
+
+// Events returns the event bus for counter state transitions
+func (engine *Engine) Events() *EventBus {
+	return &engine.events
+}
+
+// publishEvent sends a counter event to the subscribers and adds it to the timing log.
+// The caller must hold engine.mutex.
+func (engine *Engine) publishEvent(eventType string, counter int) {
+	engine.publish(engine.counterEvent(eventType, counter))
+}
+
+func (engine *Engine) publish(e Event) {
+	debug.Printf("Counter event: %#v", e)
+	engine.logEvent(e)
+	engine.events.publish(e)
+}
+
+// counterEvent creates an event from the current counter state
+func (engine *Engine) counterEvent(eventType string, counter int) Event {
+	t := engine.timeSource.Now()
+	c := engine.Counters[counter]
+	out := c.Output(t)
+	e := Event{
+		Clock:     engine.uuid,
+		Type:      eventType,
+		Counter:   counter,
+		Time:      t,
+		Countdown: out.Countdown,
+		Seconds:   int(out.Diff.Truncate(time.Second).Seconds()),
+		Text:      out.Text,
+	}
+	if out.Countdown {
+		e.Duration = int(c.state.duration.Round(time.Second).Seconds())
+	}
+
+	if s := engine.currentSegment(); s != nil && s.Counter == counter {
+		e.Segment = engine.rundown.current
+		e.Title = s.Title
+	} else {
+		for _, source := range engine.sources {
+			if source.counter == c {
+				e.Title = source.title
+				break
+			}
+		}
+	}
+	return e
+}
+
+// checkEvents publishes the threshold crossings and expiry of countdowns
+func (engine *Engine) checkEvents(t time.Time) {
+	for i, counter := range engine.Counters {
+		s := &engine.eventStates[i]
+		if !counter.active || !counter.countdown || counter.media != nil || counter.slave != nil {
+			*s = eventState{}
+			continue
+		}
+
+		diff := counter.Diff(t)
+		warning, end := engine.eventThresholds(counter)
+		for _, threshold := range []struct {
+			above     *bool
+			duration  time.Duration
+			eventType string
+		}{
+			{&s.warning, warning, EventWarning},
+			{&s.end, end, EventEnd},
+			{&s.expire, 0, EventExpire},
+		} {
+			if diff > threshold.duration {
+				*threshold.above = true
+			} else if *threshold.above {
+				*threshold.above = false
+				engine.publishEvent(threshold.eventType, i)
+			}
+		}
+	}
+}
+
+// eventThresholds returns the warning and end thresholds of a countdown. With a
+// threshold profile the warning is its highest step and the end its lowest step,
+// so the events follow the signal colors of the counter.
+func (engine *Engine) eventThresholds(counter *Counter) (warning, end time.Duration) {
+	if len(counter.thresholds) == 0 {
+		return engine.signalThresholdWarning, engine.signalThresholdEnd
+	}
+	duration := counter.state.duration
+	warning = counter.thresholds[0].at(duration)
+	end = warning
+	for i := range counter.thresholds[1:] {
+		threshold := counter.thresholds[i+1].at(duration)
+		if threshold > warning {
+			warning = threshold
+		}
+		if threshold < end {
+			end = threshold
+		}
+	}
+	return warning, end
+}
@@ -0,0 +1,49 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventThresholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds []string
+		warning    time.Duration // Time left when the warning event is expected
+		end        time.Duration // Time left when the end event is expected
+	}{
+		{"global thresholds", nil, 120 * time.Second, 60 * time.Second},
+		{"profile", []string{"1 300 #FFFF00, 10% #FF8000, 30 #FF0000"}, 300 * time.Second, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, fake := newTestEngine(t, func(o *EngineOptions) {
+				o.Thresholds = tt.thresholds
+			})
+			events := engine.events.Subscribe()
+			engine.StartCounter(1, true, 10*time.Minute)
+			<-events // Start
+
+			check := func(left time.Duration) []string {
+				fake.Set(testStart.Add(10*time.Minute - left))
+				engine.mutex.Lock()
+				engine.checkCounters()
+				engine.mutex.Unlock()
+				var types []string
+				for len(events) > 0 {
+					types = append(types, (<-events).Type)
+				}
+				return types
+			}
+
+			check(tt.warning + time.Second)
+			if got := check(tt.warning - time.Second); len(got) != 1 || got[0] != EventWarning {
+				t.Errorf("events at %v left = %v, want warning", tt.warning-time.Second, got)
+			}
+			if got := check(tt.end - time.Second); len(got) != 1 || got[0] != EventEnd {
+				t.Errorf("events at %v left = %v, want end", tt.end-time.Second, got)
+			}
+		})
+	}
+}
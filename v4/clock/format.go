@@ -0,0 +1,112 @@
+package clock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+ * User defined time format strings
+ */
+
+// ValidateFormat checks the syntax of a source format string. The directives are
+// %H, %M and %S for the hours, minutes and seconds on the clock, %D for whole days
+// and %h for the hours without the days of long timers, %t for tenths and %f for
+// hundredths of a second. The current date and time are available with %I (12 hour clock),
+// %p (AM/PM), %a, %A, %d, %m, %b, %B, %y and %Y. A dash after the % removes the
+// zero padding, eg. "%-Mm %Ss", and %% is a literal percent sign.
+func ValidateFormat(format string) error {
+	_, err := expandFormat(format, &Clock{}, time.Time{})
+	return err
+}
+
+// expandFormat builds the clock text from the format string. The time fields come
+// from the clock and the date fields from the given time.
+func expandFormat(format string, c *Clock, t time.Time) (string, error) {
+	var b strings.Builder
+	hours := abs(c.Hours)
+	days := abs(c.Days)
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		pad := true
+		if i < len(format) && format[i] == '-' {
+			pad = false
+			i++
+		}
+		if i >= len(format) {
+			return "", fmt.Errorf("incomplete directive at the end of format: %q", format)
+		}
+
+		number := func(n int) {
+			if pad {
+				fmt.Fprintf(&b, "%02d", n)
+			} else {
+				fmt.Fprintf(&b, "%d", n)
+			}
+		}
+
+		switch format[i] {
+		case 'H':
+			number(hours)
+		case 'h':
+			number(hours - days*24)
+		case 'M':
+			number(abs(c.Minutes))
+		case 'S':
+			number(abs(c.Seconds))
+		case 'D':
+			fmt.Fprintf(&b, "%d", days)
+		case 't':
+			fmt.Fprintf(&b, "%d", int(c.Fraction*10))
+		case 'f':
+			number(int(c.Fraction * 100))
+		case 'I':
+			h := t.Hour() % 12
+			if h == 0 {
+				h = 12
+			}
+			number(h)
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Format("Monday"))
+		case 'd':
+			number(t.Day())
+		case 'm':
+			number(int(t.Month()))
+		case 'b':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Format("January"))
+		case 'y':
+			number(t.Year() % 100)
+		case 'Y':
+			fmt.Fprintf(&b, "%d", t.Year())
+		case '%':
+			b.WriteByte('%')
+		default:
+			return "", fmt.Errorf("unknown directive %%%c in format: %q", format[i], format)
+		}
+	}
+	return b.String(), nil
+}
+
+// applyFormat replaces the clock text with the formatted one if the source has
+// a format string for the clock state
+func applyFormat(c *Clock, format string, t time.Time) {
+	if format == "" || c.Text == "" {
+		return
+	}
+	if text, err := expandFormat(format, c, t); err == nil {
+		c.Text = text
+		c.Formatted = true
+	}
+}
@@ -0,0 +1,41 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandFormat(t *testing.T) {
+	c := &Clock{Hours: 1, Minutes: 2, Seconds: 3, Fraction: 0.456}
+	now := time.Date(2021, 3, 1, 14, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		format string
+		text   string
+	}{
+		{"%H:%M:%S", "01:02:03"},
+		{"%-Mm %Ss", "2m 03s"},
+		{"%M:%S.%t", "02:03.4"},
+		{"%M:%S.%f", "02:03.45"},
+		{"%I %p", "02 PM"},
+		{"100%%", "100%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			text, err := expandFormat(tt.format, c, now)
+			if err != nil {
+				t.Fatalf("expandFormat: %v", err)
+			}
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+		})
+	}
+
+	for _, format := range []string{"%", "%x"} {
+		if err := ValidateFormat(format); err == nil {
+			t.Errorf("ValidateFormat(%q) succeeded, want an error", format)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package clock
+
+import (
+	"fmt"
+	"github.com/stanchan/go-osc/osc"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Named counter groups
+ */
+
+var groupNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// counterGroup is a named set of counters controlled together
+type counterGroup struct {
+	name     string
+	counters []int
+}
+
+// ValidateGroup checks the syntax of a counter group in the form of
+// "name counters", where the counters are separated by commas, eg. "stage 1,3".
+// The name can contain letters, numbers, dashes and underscores.
+func ValidateGroup(spec string, counters int) error {
+	_, err := parseGroup(spec, counters)
+	return err
+}
+
+func parseGroup(spec string, counters int) (*counterGroup, error) {
+	parts := strings.SplitN(strings.TrimSpace(spec), " ", 2)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("expected group name and counters: %q", spec)
+	}
+	g := counterGroup{name: parts[0]}
+	if !groupNameRegexp.MatchString(g.name) {
+		return nil, fmt.Errorf("invalid group name: %s", g.name)
+	}
+	for _, s := range strings.Split(parts[1], ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter number: %s", strings.TrimSpace(s))
+		} else if n < 0 || n >= counters {
+			return nil, fmt.Errorf("counter number %d out of range (have %d counters)", n, counters)
+		}
+		g.counters = append(g.counters, n)
+	}
+	return &g, nil
+}
+
+// loadGroups adds the counter groups from the configuration
+func (engine *Engine) loadGroups(specs []string) error {
+	for _, spec := range specs {
+		g, err := parseGroup(spec, len(engine.Counters))
+		if err != nil {
+			return fmt.Errorf("counter group %q: %v", spec, err)
+		}
+		if engine.findGroup(g.name) != nil {
+			return fmt.Errorf("counter group %q: duplicate name %s", spec, g.name)
+		}
+		engine.groups = append(engine.groups, g)
+		log.Printf("Counter group %s: %v", g.name, g.counters)
+	}
+	return nil
+}
+
+func (engine *Engine) findGroup(name string) *counterGroup {
+	for _, g := range engine.groups {
+		if g.name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// groupCommand runs a timer command on every counter of the group by rewriting
+// /clock/group/name/... to /clock/timer/N/... for each member
+func (engine *Engine) groupCommand(name string, msg *osc.Message) error {
+	g := engine.findGroup(name)
+	if g == nil {
+		return fmt.Errorf("unknown counter group: %s", name)
+	}
+	prefix := "/clock/group/" + name + "/"
+	if !strings.HasPrefix(msg.Address, prefix) {
+		return fmt.Errorf("invalid group command: %s", msg.Address)
+	}
+	command := strings.TrimPrefix(msg.Address, prefix)
+
+	for _, counter := range g.counters {
+		m := osc.NewMessage(fmt.Sprintf("/clock/timer/%d/%s", counter, command), msg.Arguments...)
+		decoded := engine.commands.decode(m)
+		if len(decoded) == 0 {
+			return fmt.Errorf("unknown or invalid timer command: %s", command)
+		}
+		for _, d := range decoded {
+			engine.handleMessage(d)
+		}
+	}
+	return nil
+}
+
+// groupFeedback appends the group membership messages to the feedback bundle
+func (engine *Engine) groupFeedback(bundle *osc.Bundle) {
+	for _, g := range engine.groups {
+		args := []interface{}{engine.uuid}
+		for _, c := range g.counters {
+			args = append(args, int32(c))
+		}
+		bundle.Append(osc.NewMessage(fmt.Sprintf("/clock/group/%s/state", g.name), args...))
+	}
+}
@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"github.com/stanchan/go-osc/osc"
+	"testing"
+	"time"
+)
+
+func TestParseGroup(t *testing.T) {
+	g, err := parseGroup("stage 1, 3", 4)
+	if err != nil {
+		t.Fatalf("parseGroup: %v", err)
+	}
+	if g.name != "stage" || len(g.counters) != 2 || g.counters[0] != 1 || g.counters[1] != 3 {
+		t.Errorf("parseGroup = %+v, want stage [1 3]", g)
+	}
+
+	for _, spec := range []string{"stage", "stage/1 1", "stage 1,x", "stage 4", "stage -1"} {
+		if _, err := parseGroup(spec, 4); err == nil {
+			t.Errorf("parseGroup(%q) did not return an error", spec)
+		}
+	}
+}
+
+func TestGroupCommand(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.Groups = []string{"stage 1,2"}
+	})
+	run := func(address string, args ...interface{}) error {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
+		return engine.groupCommand("stage", osc.NewMessage(address, args...))
+	}
+
+	if err := run("/clock/group/stage/countdown", int32(300)); err != nil {
+		t.Fatalf("group countdown: %v", err)
+	}
+	fake.Advance(time.Minute)
+	for i, c := range engine.State().Clocks {
+		if c.Text != "00:04:00" {
+			t.Errorf("source %d text = %q, want 00:04:00", i+1, c.Text)
+		}
+	}
+
+	if err := run("/clock/group/stage/pause"); err != nil {
+		t.Fatalf("group pause: %v", err)
+	}
+	for i, c := range engine.State().Clocks {
+		if !c.Paused {
+			t.Errorf("source %d not paused", i+1)
+		}
+	}
+
+	if err := run("/clock/group/stage/sideways"); err == nil {
+		t.Errorf("unknown timer command did not return an error")
+	}
+	engine.mutex.Lock()
+	err := engine.groupCommand("backstage", osc.NewMessage("/clock/group/backstage/pause"))
+	engine.mutex.Unlock()
+	if err == nil {
+		t.Errorf("unknown group did not return an error")
+	}
+}
@@ -0,0 +1,107 @@
+package clock
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"time"
+)
+
+/*
+ * Undo history of counter operations
+ */
+
+// maxHistory is the number of undoable operations kept for each counter
+const maxHistory = 20
+
+// counterSnapshot is the counter state before an operation
+type counterSnapshot struct {
+	state          counterState
+	active         bool
+	countdown      bool
+	paused         bool
+	rate           float64
+	signalColor    color.RGBA
+	autoColorState int
+	laps           []time.Duration
+}
+
+func (counter *Counter) snapshot() counterSnapshot {
+	return counterSnapshot{
+		state:          *counter.state,
+		active:         counter.active,
+		countdown:      counter.countdown,
+		paused:         counter.paused,
+		rate:           counter.rate,
+		signalColor:    counter.signalColor,
+		autoColorState: counter.autoColorState,
+		laps:           append([]time.Duration(nil), counter.laps...),
+	}
+}
+
+func (counter *Counter) restore(s counterSnapshot) {
+	state := s.state
+	counter.state = &state
+	counter.active = s.active
+	counter.countdown = s.countdown
+	counter.paused = s.paused
+	counter.rate = s.rate
+	counter.signalColor = s.signalColor
+	counter.autoColorState = s.autoColorState
+	counter.laps = s.laps
+}
+
+// saveHistory stores the counter state before an operation so it can be undone.
+// Counters driven by media players and other clocks have no history.
+func (engine *Engine) saveHistory(counter int) {
+	c := engine.Counters[counter]
+	if c.media != nil || c.slave != nil {
+		return
+	}
+	h := engine.history[counter]
+	if len(h) >= maxHistory {
+		h = h[1:]
+	}
+	engine.history[counter] = append(h, c.snapshot())
+}
+
+// UndoCounter reverts the last operation on a counter, restoring its previous
+// target, duration, paused state, rate and signal color
+func (engine *Engine) UndoCounter(counter int) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.undoCounter(counter)
+}
+
+func (engine *Engine) undoCounter(counter int) error {
+	if counter < 0 || counter >= len(engine.Counters) {
+		return fmt.Errorf("counter number %d out of range (have %d counters)", counter, len(engine.Counters))
+	}
+	h := engine.history[counter]
+	if len(h) == 0 {
+		return fmt.Errorf("nothing to undo on counter %d", counter)
+	}
+	c := engine.Counters[counter]
+	wasActive, wasPaused := c.active, c.paused
+	engine.history[counter] = h[:len(h)-1]
+	c.restore(h[len(h)-1])
+	log.Printf("Undo on counter %d, %d operations left in history", counter, len(h)-1)
+
+	if c.active {
+		engine.activateSourceByCounter(counter)
+	}
+	// Publish the restored state so the event consumers and timing log follow the undo
+	switch {
+	case !c.active:
+		if wasActive {
+			engine.publishEvent(EventStop, counter)
+		}
+	case c.paused:
+		engine.publishEvent(EventPause, counter)
+	case wasActive && wasPaused:
+		engine.publishEvent(EventResume, counter)
+	default:
+		engine.publishEvent(EventStart, counter)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUndoRate(t *testing.T) {
+	engine, fake := newTestEngine(t, nil)
+	engine.StartCounter(1, true, 10*time.Minute)
+	fake.Advance(time.Minute)
+
+	if err := engine.SetCounterRate(1, 2); err != nil {
+		t.Fatalf("SetCounterRate: %v", err)
+	}
+	fake.Advance(time.Minute)
+	if text := engine.State().Clocks[0].Text; text != "00:07:00" {
+		t.Errorf("text at double rate = %q, want 00:07:00", text)
+	}
+
+	if err := engine.UndoCounter(1); err != nil {
+		t.Fatalf("UndoCounter: %v", err)
+	}
+	if rate := engine.Counters[1].speed(); rate != 1 {
+		t.Errorf("rate after undo = %v, want 1", rate)
+	}
+	if text := engine.State().Clocks[0].Text; text != "00:08:00" {
+		t.Errorf("text after undo = %q, want 00:08:00", text)
+	}
+
+	// Invalid rates don't leave anything to undo
+	if err := engine.SetCounterRate(1, 0); err == nil {
+		t.Errorf("SetCounterRate accepted rate 0")
+	}
+	if err := engine.UndoCounter(1); err != nil {
+		t.Fatalf("undo of the start: %v", err)
+	}
+	if err := engine.UndoCounter(1); err == nil {
+		t.Errorf("undo succeeded with empty history")
+	}
+}
+
+func TestUndoEvents(t *testing.T) {
+	engine, fake := newTestEngine(t, nil)
+	lastEvent := func() string {
+		events := engine.TimingLog()
+		if len(events) == 0 {
+			return ""
+		}
+		return events[len(events)-1].Type
+	}
+	undo := func(want string) {
+		t.Helper()
+		n := len(engine.TimingLog())
+		if err := engine.UndoCounter(1); err != nil {
+			t.Fatalf("UndoCounter: %v", err)
+		}
+		if want == "" {
+			if len(engine.TimingLog()) != n {
+				t.Errorf("undo published %s, want no event", lastEvent())
+			}
+		} else if e := lastEvent(); e != want {
+			t.Errorf("undo published %q, want %q", e, want)
+		}
+	}
+
+	engine.StartCounter(1, true, 10*time.Minute)
+	fake.Advance(time.Minute)
+	engine.StopCounter(1)
+	engine.mutex.Lock()
+	engine.sources[0].hidden = true
+	engine.mutex.Unlock()
+
+	// Undo of the stop restarts the countdown and shows its source
+	undo(EventStart)
+	if c := engine.State().Clocks[0]; c.Hidden || c.Text != "00:09:00" {
+		t.Errorf("after undo of stop: hidden %v, text %q, want a visible 00:09:00", c.Hidden, c.Text)
+	}
+
+	engine.PauseCounter(1)
+	undo(EventResume)
+	engine.PauseCounter(1)
+	engine.ResumeCounter(1)
+	undo(EventPause)
+
+	// Undo of the start and pause leaves the counter stopped
+	undo(EventResume)
+	undo(EventStop)
+	if c := engine.State().Clocks[0]; c.Mode == Countdown {
+		t.Errorf("counter still counting down after undo of the start")
+	}
+}
@@ -0,0 +1,192 @@
+package clock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Ordered source inputs
+ */
+
+// Source input kinds
+const (
+	inputLTC     = iota // LTC timecode, if a signal is present
+	inputTimer   = iota // The source counter, if active
+	inputCounter = iota // Another counter, if active
+	inputTod     = iota // Time of day
+	inputDate    = iota // Current date
+	inputUTC     = iota // Time of day in UTC
+	inputLabel   = iota // Fixed text
+	inputShow    = iota // Show over/under against the hard out, if set
+)
+
+// sourceInput is a single entry in the ordered input list of a source
+type sourceInput struct {
+	kind    int
+	counter int            // Counter number for inputCounter
+	tz      *time.Location // Time zone for inputTod and inputDate, source time zone if nil
+	text    string         // Text for inputLabel
+}
+
+// ValidateInputs checks the syntax of a source input list. The list is comma separated
+// and evaluated in order, the first active input is displayed. Inputs are ltc, timer,
+// counter:N, tod, tod:ZONE, date, date:ZONE, utc, show and label:TEXT,
+// eg. "counter:3, counter:4, tod:Asia/Tokyo".
+func ValidateInputs(spec string, counters int) error {
+	_, err := parseInputs(spec, counters)
+	return err
+}
+
+func parseInputs(spec string, counters int) ([]sourceInput, error) {
+	var inputs []sourceInput
+	if strings.TrimSpace(spec) == "" {
+		// Source without inputs is always blank
+		return nil, nil
+	}
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		kind, arg := s, ""
+		if i := strings.Index(s, ":"); i >= 0 {
+			kind, arg = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+		}
+
+		in := sourceInput{}
+		switch kind {
+		case "ltc":
+			in.kind = inputLTC
+		case "timer":
+			in.kind = inputTimer
+		case "utc":
+			in.kind = inputUTC
+		case "show":
+			in.kind = inputShow
+		case "counter":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid counter number: %q", s)
+			} else if n < 0 || n >= counters {
+				return nil, fmt.Errorf("counter number %d out of range (have %d counters)", n, counters)
+			}
+			in.kind = inputCounter
+			in.counter = n
+		case "tod", "date":
+			in.kind = inputTod
+			if kind == "date" {
+				in.kind = inputDate
+			}
+			if arg != "" {
+				tz, err := time.LoadLocation(arg)
+				if err != nil {
+					return nil, fmt.Errorf("unknown time zone: %s", arg)
+				}
+				in.tz = tz
+			}
+		case "label":
+			if arg == "" {
+				return nil, fmt.Errorf("empty label: %q", s)
+			}
+			in.kind = inputLabel
+			in.text = arg
+		default:
+			return nil, fmt.Errorf("unknown input: %q", s)
+		}
+		if arg != "" && (in.kind == inputLTC || in.kind == inputTimer || in.kind == inputUTC || in.kind == inputShow) {
+			return nil, fmt.Errorf("input %s takes no parameter: %q", kind, s)
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+// defaultInputs returns the input list for the legacy ltc, timer and tod flags
+func defaultInputs(ltc, timer, tod bool) string {
+	var inputs []string
+	if ltc {
+		inputs = append(inputs, "ltc")
+	}
+	if timer {
+		inputs = append(inputs, "timer")
+	}
+	if tod {
+		inputs = append(inputs, "tod")
+	}
+	return strings.Join(inputs, ", ")
+}
+
+// inputCounter returns the counter of a timer or counter input
+func (engine *Engine) inputCounter(s *source, in *sourceInput) *Counter {
+	if in.kind == inputCounter {
+		return engine.Counters[in.counter]
+	}
+	return s.counter
+}
+
+// sourceState fills the clock state from the first active input of the source
+func (engine *Engine) sourceState(c *Clock, s *source, t time.Time) {
+	for i := range s.inputs {
+		in := &s.inputs[i]
+		switch in.kind {
+		case inputLTC:
+			if engine.ltcActive {
+				engine.ltcState(c, s)
+				return
+			}
+		case inputTimer, inputCounter:
+			if counter := engine.inputCounter(s, in); counter.active {
+				engine.timerState(c, s, counter, t)
+				return
+			}
+		case inputTod:
+			tz := s.tz
+			if in.tz != nil {
+				tz = in.tz
+			}
+			engine.todState(c, s, tz, t)
+			return
+		case inputUTC:
+			engine.todState(c, s, time.UTC, t)
+			return
+		case inputDate:
+			tz := s.tz
+			if in.tz != nil {
+				tz = in.tz
+			}
+			dateState(c, tz, t)
+			return
+		case inputShow:
+			if show := engine.showState(t); show.Active {
+				engine.overUnderState(c, show)
+				return
+			}
+		case inputLabel:
+			c.Mode = Label
+			c.Text = in.text
+			c.Compact = fmt.Sprintf("%.4s", in.text)
+			return
+		}
+	}
+}
+
+// signalCounter returns the counter whose signal color the source shows when no
+// counter input is active, nil if the source has no counter inputs
+func (engine *Engine) signalCounter(s *source) *Counter {
+	for i := range s.inputs {
+		if k := s.inputs[i].kind; k == inputTimer || k == inputCounter {
+			return engine.inputCounter(s, &s.inputs[i])
+		}
+	}
+	return nil
+}
+
+func dateState(c *Clock, tz *time.Location, t time.Time) {
+	local := t.In(tz)
+	c.Mode = Date
+	c.Text = local.Format("2006-01-02")
+	c.Compact = local.Format("02.01")
+	c.Hours = local.Day()
+	c.Minutes = int(local.Month())
+	c.Seconds = local.Year() % 100
+}
@@ -0,0 +1,204 @@
+package clock
+
+import (
+	"fmt"
+	"github.com/stanchan/go-osc/osc"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Linked counters following another counter
+ */
+
+// counterLink makes a counter follow a source counter with an offset and scale
+type counterLink struct {
+	counter int
+	source  int
+	offset  time.Duration // Added to the scaled source counter time
+	scale   float64       // Multiplier for the source counter time
+}
+
+// ValidateLink checks the syntax of a linked counter in the form of
+// "counter source [offset] [scale]", eg. "2 1 -5:00" for a counter showing
+// counter 1 minus five minutes. The offset is in [-][[HH:]MM:]SS format and
+// the scale is a multiplier for the source counter time.
+func ValidateLink(spec string, counters int) error {
+	_, err := parseLink(strings.Fields(spec), counters)
+	return err
+}
+
+func parseLink(fields []string, counters int) (*counterLink, error) {
+	if len(fields) < 2 || len(fields) > 4 {
+		return nil, fmt.Errorf("expected counter, source counter, optional offset and scale: %q", strings.Join(fields, " "))
+	}
+	l := counterLink{scale: 1}
+	for i, p := range []*int{&l.counter, &l.source} {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter number: %s", fields[i])
+		} else if n < 0 || n >= counters {
+			return nil, fmt.Errorf("counter number %d out of range (have %d counters)", n, counters)
+		}
+		*p = n
+	}
+	if l.counter == l.source {
+		return nil, fmt.Errorf("counter %d can't be linked to itself", l.counter)
+	}
+	if len(fields) > 2 {
+		s := fields[2]
+		negative := strings.HasPrefix(s, "-")
+		d, err := ParseDuration(strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset: %s", s)
+		}
+		if negative {
+			d = -d
+		}
+		l.offset = d
+	}
+	if len(fields) > 3 {
+		scale, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil || scale <= 0 || scale > maxRate {
+			return nil, fmt.Errorf("invalid scale: %s", fields[3])
+		}
+		l.scale = scale
+	}
+	return &l, nil
+}
+
+// loadLinks sets up the linked counters from the configuration
+func (engine *Engine) loadLinks(specs []string) error {
+	for _, spec := range specs {
+		l, err := parseLink(strings.Fields(spec), len(engine.Counters))
+		if err != nil {
+			return fmt.Errorf("timer link %q: %v", spec, err)
+		}
+		if err := engine.addLink(l); err != nil {
+			return fmt.Errorf("timer link %q: %v", spec, err)
+		}
+	}
+	return nil
+}
+
+// SetLink makes a counter follow a source counter. The spec is
+// "source [offset] [scale]" as in ValidateLink, an empty spec removes the link
+// and leaves the counter running on its own.
+func (engine *Engine) SetLink(counter int, spec string) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.setLink(counter, spec)
+}
+
+func (engine *Engine) setLink(counter int, spec string) error {
+	if counter < 0 || counter >= len(engine.Counters) {
+		return fmt.Errorf("counter number %d out of range (have %d counters)", counter, len(engine.Counters))
+	}
+	if strings.TrimSpace(spec) == "" {
+		engine.removeLink(counter)
+		return nil
+	}
+	l, err := parseLink(append([]string{strconv.Itoa(counter)}, strings.Fields(spec)...), len(engine.Counters))
+	if err != nil {
+		return err
+	}
+	old := engine.findLink(counter)
+	engine.removeLink(counter)
+	if err := engine.addLink(l); err != nil {
+		if old != nil {
+			engine.links = append(engine.links, old)
+		}
+		return err
+	}
+	return nil
+}
+
+func (engine *Engine) addLink(l *counterLink) error {
+	if engine.findLink(l.counter) != nil {
+		return fmt.Errorf("counter %d is already linked", l.counter)
+	}
+	// Chained links would depend on the update order, so only plain counters can be followed
+	if engine.findLink(l.source) != nil {
+		return fmt.Errorf("source counter %d is itself linked", l.source)
+	}
+	for _, other := range engine.links {
+		if other.source == l.counter {
+			return fmt.Errorf("counter %d is the source of counter %d", l.counter, other.counter)
+		}
+	}
+	engine.links = append(engine.links, l)
+	engine.followLink(l)
+	log.Printf("Counter %d linked to counter %d, offset %v, scale %v", l.counter, l.source, l.offset, l.scale)
+	return nil
+}
+
+func (engine *Engine) removeLink(counter int) {
+	for i, l := range engine.links {
+		if l.counter == counter {
+			engine.links = append(engine.links[:i], engine.links[i+1:]...)
+			log.Printf("Counter %d unlinked from counter %d", counter, l.source)
+			return
+		}
+	}
+}
+
+func (engine *Engine) findLink(counter int) *counterLink {
+	for _, l := range engine.links {
+		if l.counter == counter {
+			return l
+		}
+	}
+	return nil
+}
+
+// linkFeedback adds the link of each linked counter to the feedback bundle
+func (engine *Engine) linkFeedback(bundle *osc.Bundle) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	for _, l := range engine.links {
+		bundle.Append(osc.NewMessage(fmt.Sprintf("/clock/timer/%d/link", l.counter), engine.uuid, int32(l.source), int32(l.offset.Seconds()), float32(l.scale)))
+	}
+}
+
+// syncLinks updates all linked counters from their sources, so that starting,
+// pausing, modifying and stopping the source carries over to them
+func (engine *Engine) syncLinks() {
+	for _, l := range engine.links {
+		engine.followLink(l)
+	}
+}
+
+func (engine *Engine) followLink(l *counterLink) {
+	engine.Counters[l.counter].follow(engine.Counters[l.source], l.offset, l.scale)
+}
+
+// follow copies the timer state of the source counter with the offset and scale
+// applied. The scale is carried by the counting rate, so the linked counter keeps
+// running smoothly between updates. The signal color and thresholds aren't
+// touched, the linked counter keeps its own.
+func (counter *Counter) follow(src *Counter, offset time.Duration, scale float64) {
+	if !src.active || src.media != nil || src.slave != nil {
+		if counter.active {
+			counter.Stop()
+		}
+		return
+	}
+	counter.rate = scale * src.speed()
+	counter.active = true
+	counter.countdown = src.countdown
+	counter.paused = src.paused
+
+	// Moving the target by the offset in real time at the linked rate
+	shift := counter.unscale(offset)
+	target := src.state.target.Add(-shift)
+	if src.countdown {
+		target = src.state.target.Add(shift)
+	}
+	*counter.state = counterState{
+		target:   target,
+		duration: time.Duration(float64(src.state.duration)*scale) + offset,
+		left:     time.Duration(float64(src.state.left)*scale) + offset,
+	}
+}
@@ -0,0 +1,162 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCounterFollow(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset time.Duration
+		scale  float64
+		run    func(src *Counter, fake *FakeTime)
+		active bool
+		paused bool
+		text   string
+	}{
+		{
+			name:   "countdown with a negative offset",
+			offset: -5 * time.Minute,
+			scale:  1,
+			run: func(src *Counter, fake *FakeTime) {
+				src.Start(true, 10*time.Minute)
+				fake.Advance(time.Minute)
+			},
+			active: true,
+			text:   "00:04:00",
+		},
+		{
+			name:   "countdown at double scale",
+			offset: 0,
+			scale:  2,
+			run: func(src *Counter, fake *FakeTime) {
+				src.Start(true, 10*time.Minute)
+				fake.Advance(time.Minute)
+			},
+			active: true,
+			text:   "00:18:00",
+		},
+		{
+			name:   "count up with an offset and half scale",
+			offset: time.Minute,
+			scale:  0.5,
+			run: func(src *Counter, fake *FakeTime) {
+				src.Start(false, 0)
+				fake.Advance(2 * time.Minute)
+			},
+			active: true,
+			text:   "00:02:00",
+		},
+		{
+			name:   "source at a rate",
+			offset: time.Minute,
+			scale:  2,
+			run: func(src *Counter, fake *FakeTime) {
+				src.Start(false, 0)
+				src.SetRate(2)
+				fake.Advance(time.Minute)
+			},
+			active: true,
+			text:   "00:05:00",
+		},
+		{
+			name:   "paused source",
+			offset: -5 * time.Minute,
+			scale:  1,
+			run: func(src *Counter, fake *FakeTime) {
+				src.Start(true, 10*time.Minute)
+				fake.Advance(time.Minute)
+				src.Pause()
+				fake.Advance(time.Hour)
+			},
+			active: true,
+			paused: true,
+			text:   "00:04:00",
+		},
+		{
+			name:   "modified source",
+			offset: -5 * time.Minute,
+			scale:  1,
+			run: func(src *Counter, fake *FakeTime) {
+				src.Start(true, 10*time.Minute)
+				src.Modify(2 * time.Minute)
+				fake.Advance(time.Minute)
+			},
+			active: true,
+			text:   "00:06:00",
+		},
+		{
+			name:   "stopped source",
+			offset: -5 * time.Minute,
+			scale:  1,
+			run: func(src *Counter, fake *FakeTime) {
+				src.Start(true, 10*time.Minute)
+				src.Stop()
+			},
+			active: false,
+			text:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeTime(testStart)
+			src := newTestCounter(fake)
+			linked := newTestCounter(fake)
+			linked.Start(true, time.Hour) // Stopped when the source is not active
+
+			tt.run(src, fake)
+			linked.follow(src, tt.offset, tt.scale)
+
+			out := linked.Output(fake.Now())
+			if out.Active != tt.active {
+				t.Fatalf("active = %v, want %v", out.Active, tt.active)
+			}
+			if out.Paused != tt.paused {
+				t.Errorf("paused = %v, want %v", out.Paused, tt.paused)
+			}
+			if out.Text != tt.text {
+				t.Errorf("text = %q, want %q", out.Text, tt.text)
+			}
+
+			// The linked counter keeps following between updates
+			fake.Advance(30 * time.Second)
+			if tt.active {
+				want := time.Duration(float64(src.Diff(fake.Now()))*tt.scale) + tt.offset
+				if diff := linked.Diff(fake.Now()); diff != want {
+					t.Errorf("diff without an update = %v, want %v", diff, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetLink(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.Links = []string{"2 1 -5:00"}
+	})
+	engine.StartCounter(1, true, 10*time.Minute)
+	fake.Advance(time.Minute)
+	if text := engine.State().Clocks[1].Text; text != "00:04:00" {
+		t.Errorf("linked counter = %q, want 00:04:00", text)
+	}
+
+	for _, spec := range []string{"2", "1 2 -1", "1 1:00 0", "1 5m", "1 1:00 2 3"} {
+		if err := engine.SetLink(3, spec); err == nil {
+			t.Errorf("SetLink(3, %q) did not return an error", spec)
+		}
+	}
+	if err := engine.SetLink(2, "2"); err == nil {
+		t.Errorf("link to itself accepted")
+	}
+
+	// Unlinked counters keep running on their own
+	if err := engine.SetLink(2, ""); err != nil {
+		t.Fatalf("SetLink: %v", err)
+	}
+	engine.StopCounter(1)
+	if text := engine.State().Clocks[1].Text; text != "00:04:00" {
+		t.Errorf("unlinked counter = %q, want 00:04:00", text)
+	}
+}
@@ -26,6 +26,8 @@ type Message struct {
 	MediaMessage       *MediaMessage
 	DisplayTextMessage *displayTextMessage
 	Colors             []color.RGBA
+	Segment            *Segment
+	Command            *osc.Message // Original command for group messages
 }
 
 // MediaMessage contains data from media players
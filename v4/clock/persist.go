@@ -0,0 +1,189 @@
+package clock
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stanchan/clock-8001/v4/debug"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/*
+ * Persistence of the timer and source state over clock restarts
+ */
+
+// persistedState is the on-disk snapshot of the engine state
+type persistedState struct {
+	Saved     time.Time          `json:"saved"`
+	Counters  []persistedCounter `json:"counters"`
+	Sources   []persistedSource  `json:"sources"`
+	TimingLog []Event            `json:"timing_log,omitempty"`
+}
+
+type persistedCounter struct {
+	Active      bool          `json:"active"`
+	Countdown   bool          `json:"countdown"`
+	Paused      bool          `json:"paused"`
+	Target      time.Time     `json:"target"`
+	Duration    time.Duration `json:"duration"`
+	Left        time.Duration `json:"left"`
+	SignalColor color.RGBA    `json:"signal_color"`
+	Rate        float64       `json:"rate,omitempty"`
+}
+
+type persistedSource struct {
+	DefaultTitle  string       `json:"default_title"`            // Title from the config when the snapshot was taken
+	DefaultHidden bool         `json:"default_hidden"`           // Hidden flag from the config when the snapshot was taken
+	DefaultColors []color.RGBA `json:"default_colors,omitempty"` // Colors from the config when the snapshot was taken
+	Title         string       `json:"title"`
+	Hidden        bool         `json:"hidden"`
+	Colors        []color.RGBA `json:"colors,omitempty"` // Text and background colors if set over OSC
+}
+
+// snapshot collects the persisted state. The caller must hold engine.mutex.
+func (engine *Engine) snapshot() *persistedState {
+	p := persistedState{
+		Counters:  make([]persistedCounter, len(engine.Counters)),
+		Sources:   make([]persistedSource, len(engine.sources)),
+		TimingLog: append([]Event(nil), engine.timingLog...),
+	}
+
+	for i, c := range engine.Counters {
+		if c.media != nil || c.slave != nil {
+			// Externally driven counters are refreshed by their sources
+			continue
+		}
+		p.Counters[i] = persistedCounter{
+			Active:      c.active,
+			Countdown:   c.countdown,
+			Paused:      c.paused,
+			Target:      c.state.target,
+			Duration:    c.state.duration,
+			Left:        c.state.left,
+			SignalColor: c.signalColor,
+			Rate:        c.rate,
+		}
+	}
+
+	for i, s := range engine.sources {
+		p.Sources[i] = persistedSource{
+			DefaultTitle:  s.defaultTitle,
+			DefaultHidden: s.defaultHidden,
+			Title:         s.title,
+			Hidden:        s.hidden,
+		}
+		if s.colorOverride {
+			p.Sources[i].DefaultColors = s.defaultColors
+			p.Sources[i].Colors = []color.RGBA{s.textColor, s.bgColor}
+		}
+	}
+	return &p
+}
+
+// saveState writes the state file if the persisted state has changed
+func (engine *Engine) saveState() {
+	if engine.stateFile == "" {
+		return
+	}
+
+	engine.mutex.Lock()
+	p := engine.snapshot()
+	engine.mutex.Unlock()
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding clock state: %v", err)
+		return
+	}
+	if bytes.Equal(data, engine.savedState) {
+		return
+	}
+
+	// Only stamp the file when something changed, so the comparison above works
+	p.Saved = engine.timeSource.Now()
+	stamped, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding clock state: %v", err)
+		return
+	}
+
+	// Write to a temporary file first so a crash can't leave a truncated state file
+	tmp := filepath.Join(filepath.Dir(engine.stateFile), "."+filepath.Base(engine.stateFile)+".tmp")
+	if err := os.WriteFile(tmp, stamped, 0644); err != nil {
+		log.Printf("Error writing clock state: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, engine.stateFile); err != nil {
+		log.Printf("Error writing clock state: %v", err)
+		return
+	}
+	engine.savedState = data
+	debug.Printf("Clock state saved to %s", engine.stateFile)
+}
+
+// restoreState loads the counter and source state from the state file.
+// It is run from MakeEngine before any of the engine goroutines are started.
+func (engine *Engine) restoreState() error {
+	data, err := os.ReadFile(engine.stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var p persistedState
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+
+	for i, pc := range p.Counters {
+		if i >= len(engine.Counters) {
+			break
+		}
+		c := engine.Counters[i]
+		if c.media != nil || c.slave != nil {
+			continue
+		}
+		c.state = &counterState{
+			target:   pc.Target,
+			duration: pc.Duration,
+			left:     pc.Left,
+		}
+		c.active = pc.Active
+		c.countdown = pc.Countdown
+		c.paused = pc.Paused
+		c.signalColor = pc.SignalColor
+		c.rate = pc.Rate
+	}
+
+	for i, ps := range p.Sources {
+		if i >= len(engine.sources) {
+			break
+		}
+		s := engine.sources[i]
+		// Runtime overrides only survive if the configuration didn't change them
+		if ps.DefaultTitle == s.defaultTitle {
+			s.title = ps.Title
+		}
+		if ps.DefaultHidden == s.defaultHidden {
+			s.hidden = ps.Hidden
+		}
+		if len(ps.Colors) == 2 {
+			// The configured colors are only known once SetSourceColors is called,
+			// it drops the override if they differ from the saved ones
+			s.textColor = ps.Colors[0]
+			s.bgColor = ps.Colors[1]
+			s.defaultColors = ps.DefaultColors
+			s.colorOverride = true
+		}
+	}
+
+	// The timing log continues over restarts, eg. after saving the configuration
+	engine.timingLog = p.TimingLog
+
+	log.Printf("Restored clock state saved at %v from %s", p.Saved, engine.stateFile)
+	return nil
+}
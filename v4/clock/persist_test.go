@@ -0,0 +1,86 @@
+package clock
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForFile waits for the engine loop to write the state file
+func waitForFile(t *testing.T, path string) {
+	t.Helper()
+	deadline := time.Now().Add(3 * stateTimer)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		time.Sleep(stateTimer / 10)
+	}
+	t.Fatalf("state file %s was not written", path)
+}
+
+func TestStatePersistedWithoutOSC(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.StateFile = stateFile
+	})
+	engine.StartCounter(1, true, 10*time.Minute)
+	fake.Advance(time.Minute)
+	waitForFile(t, stateFile)
+
+	restored, _ := newTestEngine(t, func(o *EngineOptions) {
+		o.StateFile = stateFile
+		o.TimeSource = fake
+	})
+	if text := restored.State().Clocks[0].Text; text != "00:09:00" {
+		t.Errorf("restored countdown = %q, want 00:09:00", text)
+	}
+}
+
+func TestRestoreSourceColors(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.RGBA{A: 255}
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	engine, _ := newTestEngine(t, func(o *EngineOptions) {
+		o.StateFile = stateFile
+	})
+	engine.SetSourceColors(0, white, black)
+	engine.mutex.Lock()
+	engine.handleMessage(Message{Type: "sourceColors", Counter: 0, Colors: []color.RGBA{red, blue}})
+	engine.mutex.Unlock()
+	engine.saveState()
+
+	tests := []struct {
+		name   string
+		config []color.RGBA // Configured colors after the restart
+		reset  bool         // Reset the colors over OSC
+		text   color.RGBA
+		bg     color.RGBA
+	}{
+		{"unchanged configuration", []color.RGBA{white, black}, false, red, blue},
+		{"changed configuration", []color.RGBA{black, white}, false, black, white},
+		{"reset over OSC", []color.RGBA{white, black}, true, white, black},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored, _ := newTestEngine(t, func(o *EngineOptions) {
+				o.StateFile = stateFile
+			})
+			restored.SetSourceColors(0, tt.config[0], tt.config[1])
+			if tt.reset {
+				restored.mutex.Lock()
+				restored.handleMessage(Message{Type: "sourceColorsReset", Counter: 0})
+				restored.mutex.Unlock()
+			}
+			c := restored.State().Clocks[0]
+			if c.TextColor != tt.text || c.BGColor != tt.bg {
+				t.Errorf("colors = %v / %v, want %v / %v", c.TextColor, c.BGColor, tt.text, tt.bg)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package clock
+
+import (
+	"fmt"
+	"github.com/stanchan/go-osc/osc"
+	"image/color"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Named timer presets
+ */
+
+// preset is a stored timer configuration that can be loaded into any counter
+type preset struct {
+	name       string
+	duration   time.Duration
+	countdown  bool
+	title      string       // Title for the sources displaying the counter, unchanged if empty
+	colors     []color.RGBA // Optional text and background colors for the sources displaying the counter
+	thresholds string       // Optional signal threshold profile for the counter
+}
+
+// ValidatePreset checks the syntax of a timer preset in the form of
+// "name;duration;direction;title;color;background;thresholds". The duration is
+// [[HH:]MM:]SS, count ups start from zero and have no duration. The direction is
+// down or up and the rest of the fields are optional,
+// eg. "keynote;20:00;down;Keynote;#FFFFFF;#000080;300 #FFFF00, 60 #FF0000".
+func ValidatePreset(spec string) error {
+	_, err := parsePreset(spec)
+	return err
+}
+
+func parsePreset(spec string) (*preset, error) {
+	fields := strings.Split(spec, ";")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	if len(fields) < 3 || len(fields) > 7 {
+		return nil, fmt.Errorf("expected name;duration;direction[;title;color;background;thresholds]: %q", spec)
+	}
+	for len(fields) < 7 {
+		fields = append(fields, "")
+	}
+
+	p := preset{
+		name:       fields[0],
+		title:      fields[3],
+		thresholds: fields[6],
+	}
+	if p.name == "" {
+		return nil, fmt.Errorf("empty preset name")
+	} else if _, err := strconv.Atoi(p.name); err == nil {
+		return nil, fmt.Errorf("preset name can't be a number, presets are also recalled by number: %s", p.name)
+	}
+
+	switch fields[2] {
+	case "down":
+		p.countdown = true
+	case "up":
+		p.countdown = false
+	default:
+		return nil, fmt.Errorf("invalid direction, expected down or up: %s", fields[2])
+	}
+
+	// Count ups start from zero and don't need a duration
+	if fields[1] != "" || p.countdown {
+		var err error
+		if p.duration, err = ParseDuration(fields[1]); err != nil {
+			return nil, err
+		}
+	}
+	if !p.countdown && p.duration != 0 {
+		return nil, fmt.Errorf("count up presets start from zero, leave the duration empty: %s", fields[1])
+	}
+
+	if fields[4] != "" || fields[5] != "" {
+		p.colors = make([]color.RGBA, 2)
+		for i, c := range fields[4:6] {
+			p.colors[i] = color.RGBA{A: 255}
+			if _, err := fmt.Sscanf(c, "#%02x%02x%02x", &p.colors[i].R, &p.colors[i].G, &p.colors[i].B); err != nil || len(c) != 7 {
+				return nil, fmt.Errorf("invalid color, expected #RRGGBB: %q", c)
+			}
+		}
+	}
+
+	if p.thresholds != "" {
+		if _, err := parseThresholds(p.thresholds); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+// ParseDuration parses durations in [[HH:]MM:]SS format, eg. "1:30:00" or "90"
+func ParseDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("too many fields in duration: %s", s)
+	}
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+		d = d*60 + time.Duration(n)
+	}
+	return d * time.Second, nil
+}
+
+// loadPresets adds the timer presets from the configuration
+func (engine *Engine) loadPresets(specs []string) error {
+	for _, spec := range specs {
+		p, err := parsePreset(spec)
+		if err != nil {
+			return fmt.Errorf("timer preset %q: %v", spec, err)
+		}
+		if engine.findPreset(p.name) != nil {
+			return fmt.Errorf("timer preset %q: duplicate name %s", spec, p.name)
+		}
+		engine.presets = append(engine.presets, p)
+	}
+	if len(engine.presets) > 0 {
+		log.Printf("Loaded %d timer presets", len(engine.presets))
+	}
+	return nil
+}
+
+// findPreset finds a preset by its name or number starting from 1
+func (engine *Engine) findPreset(name string) *preset {
+	if n, err := strconv.Atoi(name); err == nil {
+		if n >= 1 && n <= len(engine.presets) {
+			return engine.presets[n-1]
+		}
+		return nil
+	}
+	for _, p := range engine.presets {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// LoadPreset starts the named or numbered preset on the counter
+func (engine *Engine) LoadPreset(counter int, name string) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.loadPreset(counter, name)
+}
+
+func (engine *Engine) loadPreset(counter int, name string) error {
+	if counter < 0 || counter >= len(engine.Counters) {
+		return fmt.Errorf("counter number %d out of range (have %d counters)", counter, len(engine.Counters))
+	}
+	p := engine.findPreset(name)
+	if p == nil {
+		return fmt.Errorf("unknown preset: %s", name)
+	}
+
+	log.Printf("Loading preset %s on counter %d", p.name, counter)
+	if p.thresholds != "" {
+		if err := engine.setThresholds(counter, p.thresholds); err != nil {
+			return err
+		}
+	} else {
+		// Don't keep the profile of a previously loaded preset
+		engine.Counters[counter].thresholds = engine.defaultThresholds[counter]
+		engine.Counters[counter].autoColorState = -1
+	}
+	engine.startCounter(counter, p.countdown, p.duration)
+
+	for _, source := range engine.sources {
+		if source.counter == engine.Counters[counter] {
+			if p.title != "" {
+				source.title = p.title
+			}
+			// Preset colors last until the next preset, without colors the source colors return
+			source.cueColors = nil
+			if len(p.colors) == 2 {
+				source.cueColors = p.colors
+			}
+		}
+	}
+	return nil
+}
+
+// sendPresets sends the preset list as OSC feedback
+func (engine *Engine) sendPresets() {
+	if engine.oscDests == nil {
+		return
+	}
+
+	bundle := osc.NewBundle(time.Now())
+	bundle.Append(osc.NewMessage("/clock/presets/list", engine.uuid, int32(len(engine.presets))))
+	for i, p := range engine.presets {
+		direction := "up"
+		if p.countdown {
+			direction = "down"
+		}
+		bundle.Append(osc.NewMessage("/clock/presets/entry", engine.uuid, int32(i+1), p.name, int32(p.duration.Seconds()), direction, p.title))
+	}
+
+	data, err := bundle.MarshalBinary()
+	if err != nil {
+		log.Printf("Error sending presets: %v", err)
+		return
+	}
+	engine.oscSendChan <- data
+}
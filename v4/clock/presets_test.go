@@ -0,0 +1,115 @@
+package clock
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestValidatePreset(t *testing.T) {
+	tests := []struct {
+		spec string
+		ok   bool
+	}{
+		{"keynote;20:00;down;Keynote;#FFFFFF;#000080;300 #FFFF00, 60 #FF0000", true},
+		{"break;15:00;down", true},
+		{"overrun;;up", true},
+		{"overrun;0;up", true},
+		{"overrun;5:00;up", false},
+		{"break;;down", false},
+		{"1;15:00;down", false},
+		{"break;15:00;sideways", false},
+	}
+	for _, tt := range tests {
+		if err := ValidatePreset(tt.spec); (err == nil) != tt.ok {
+			t.Errorf("ValidatePreset(%q) = %v, want success %v", tt.spec, err, tt.ok)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+		ok   bool
+	}{
+		{"90", 90 * time.Second, true},
+		{"15:00", 15 * time.Minute, true},
+		{"1:30:00", 90 * time.Minute, true},
+		{"0", 0, true},
+		{"1:00:00:00", 0, false},
+		{"-5:00", 0, false},
+		{"5m", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		d, err := ParseDuration(tt.s)
+		if (err == nil) != tt.ok || d != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v, want %v, success %v", tt.s, d, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPresetThresholds(t *testing.T) {
+	engine, _ := newTestEngine(t, func(o *EngineOptions) {
+		o.Thresholds = []string{"1 120 #FFFF00"}
+		o.Presets = []string{
+			"keynote;20:00;down;Keynote;;;300 #FFFF00, 60 #FF0000",
+			"break;15:00;down",
+		}
+	})
+
+	load := func(name string) int {
+		t.Helper()
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
+		if err := engine.loadPreset(1, name); err != nil {
+			t.Fatalf("loadPreset %s: %v", name, err)
+		}
+		return len(engine.Counters[1].thresholds)
+	}
+
+	if n := load("keynote"); n != 2 {
+		t.Errorf("keynote preset has %d threshold steps, want 2", n)
+	}
+	if n := load("break"); n != 1 {
+		t.Errorf("break preset has %d threshold steps, want the configured 1", n)
+	}
+	if d := engine.State().Clocks[0].Text; d != "00:15:00" {
+		t.Errorf("break preset text = %q, want 00:15:00", d)
+	}
+}
+
+func TestPresetColors(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.RGBA{A: 255}
+	navy := color.RGBA{B: 128, A: 255}
+	engine, _ := newTestEngine(t, func(o *EngineOptions) {
+		o.Presets = []string{
+			"keynote;20:00;down;Keynote;#FFFFFF;#000080",
+			"break;15:00;down",
+		}
+	})
+	engine.SetSourceColors(0, black, white)
+
+	steps := []struct {
+		preset string
+		text   color.RGBA
+		bg     color.RGBA
+	}{
+		{"keynote", white, navy},
+		{"break", black, white},
+	}
+	for _, s := range steps {
+		if err := engine.LoadPreset(1, s.preset); err != nil {
+			t.Fatalf("LoadPreset %s: %v", s.preset, err)
+		}
+		c := engine.State().Clocks[0]
+		if c.TextColor != s.text || c.BGColor != s.bg {
+			t.Errorf("%s: colors = %v / %v, want %v / %v", s.preset, c.TextColor, c.BGColor, s.text, s.bg)
+		}
+		if engine.sources[0].colorOverride {
+			t.Errorf("%s: preset colors marked as an OSC override", s.preset)
+		}
+	}
+}
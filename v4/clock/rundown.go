@@ -0,0 +1,146 @@
+package clock
+
+import (
+	"image/color"
+	"log"
+	"time"
+)
+
+/*
+ * Rundown of timed segments run on the counters
+ */
+
+// Segment is a single timed entry in a rundown
+type Segment struct {
+	Title       string        // Title for the sources displaying the segment counter
+	Duration    time.Duration // Countdown duration
+	Target      string        // Time of day to count down to as HH:MM:SS, used instead of Duration if set
+	Counter     int           // Counter to run the segment on
+	AutoAdvance bool          // Start the next segment when the countdown expires
+	Colors      []color.RGBA  // Optional text and background colors for the sources displaying the segment
+}
+
+type rundown struct {
+	segments []Segment
+	current  int // Number of the current segment starting from 1, 0 if the rundown hasn't been started
+}
+
+// RundownState is the rundown position at the time State() was called
+type RundownState struct {
+	Current   int    // Number of the current segment starting from 1, 0 if the rundown hasn't been started
+	Segments  int    // Number of segments in the rundown
+	Title     string // Title of the current segment
+	NextTitle string // Title of the next segment
+}
+
+// LoadRundown replaces the rundown segments and resets the rundown position
+func (engine *Engine) LoadRundown(segments []Segment) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.loadRundown(segments)
+}
+
+func (engine *Engine) loadRundown(segments []Segment) {
+	engine.rundown = rundown{}
+	for _, s := range engine.sources {
+		s.cueColors = nil
+	}
+	for _, s := range segments {
+		engine.addSegment(s)
+	}
+	log.Printf("Loaded rundown with %d segments", len(engine.rundown.segments))
+}
+
+func (engine *Engine) addSegment(s Segment) {
+	if s.Counter < 0 || s.Counter >= len(engine.Counters) {
+		log.Printf("engine.addSegment: illegal counter number %d for segment %q (have %d counters)\n", s.Counter, s.Title, len(engine.Counters))
+		return
+	}
+	engine.rundown.segments = append(engine.rundown.segments, s)
+}
+
+// startSegment makes the numbered segment current and starts its countdown
+func (engine *Engine) startSegment(number int) {
+	if number < 1 || number > len(engine.rundown.segments) {
+		log.Printf("engine.startSegment: illegal segment number %d (have %d segments)\n", number, len(engine.rundown.segments))
+		return
+	}
+
+	if prev := engine.currentSegment(); prev != nil {
+		if prev.Counter != engine.rundown.segments[number-1].Counter {
+			engine.stopCounter(prev.Counter)
+		}
+	}
+
+	engine.rundown.current = number
+	s := engine.rundown.segments[number-1]
+	if s.Target != "" {
+		log.Printf("Starting rundown segment %d: %s (until %s)", number, s.Title, s.Target)
+		engine.targetCounter(s.Counter, s.Target, true)
+	} else {
+		log.Printf("Starting rundown segment %d: %s (%v)", number, s.Title, s.Duration)
+		engine.startCounter(s.Counter, true, s.Duration)
+	}
+
+	for _, source := range engine.sources {
+		if source.counter == engine.Counters[s.Counter] {
+			source.title = s.Title
+			// Segment colors last until the next segment, without colors the source colors return
+			source.cueColors = nil
+			if len(s.Colors) == 2 {
+				source.cueColors = s.Colors
+			}
+		}
+	}
+}
+
+func (engine *Engine) rundownGo() {
+	if engine.rundown.current == 0 {
+		engine.startSegment(1)
+	} else {
+		engine.startSegment(engine.rundown.current)
+	}
+}
+
+func (engine *Engine) rundownNext() {
+	engine.startSegment(engine.rundown.current + 1)
+}
+
+func (engine *Engine) rundownPrevious() {
+	engine.startSegment(engine.rundown.current - 1)
+}
+
+// currentSegment returns the current segment, or nil if the rundown hasn't been started
+func (engine *Engine) currentSegment() *Segment {
+	if engine.rundown.current < 1 || engine.rundown.current > len(engine.rundown.segments) {
+		return nil
+	}
+	return &engine.rundown.segments[engine.rundown.current-1]
+}
+
+// checkRundown advances the rundown when an auto-advancing segment expires
+func (engine *Engine) checkRundown(t time.Time) {
+	s := engine.currentSegment()
+	if s == nil || !s.AutoAdvance || engine.rundown.current >= len(engine.rundown.segments) {
+		return
+	}
+
+	counter := engine.Counters[s.Counter]
+	if counter.expired(t) && !counter.paused {
+		engine.rundownNext()
+	}
+}
+
+func (engine *Engine) rundownState() RundownState {
+	r := RundownState{
+		Current:  engine.rundown.current,
+		Segments: len(engine.rundown.segments),
+	}
+	if s := engine.currentSegment(); s != nil {
+		r.Title = s.Title
+	}
+	if r.Current < r.Segments {
+		r.NextTitle = engine.rundown.segments[r.Current].Title
+	}
+	return r
+}
@@ -0,0 +1,72 @@
+package clock
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestRundownAutoAdvance(t *testing.T) {
+	engine, fake := newTestEngine(t, nil)
+	engine.LoadRundown([]Segment{
+		{Title: "Opening", Duration: time.Minute, Counter: 1, AutoAdvance: true},
+		{Title: "Keynote", Duration: 10 * time.Minute, Counter: 1},
+	})
+
+	engine.mutex.Lock()
+	engine.rundownGo()
+	engine.mutex.Unlock()
+	if c := engine.State().Clocks[0]; c.Label != "Opening" || c.Text != "00:01:00" {
+		t.Errorf("first segment = %q %q, want Opening 00:01:00", c.Label, c.Text)
+	}
+
+	fake.Advance(time.Minute + time.Second)
+	engine.mutex.Lock()
+	engine.checkRundown(fake.Now())
+	engine.mutex.Unlock()
+	state := engine.State()
+	if state.Rundown.Current != 2 {
+		t.Fatalf("current segment after expiry = %d, want 2", state.Rundown.Current)
+	}
+	if c := state.Clocks[0]; c.Label != "Keynote" || c.Text != "00:10:00" {
+		t.Errorf("second segment = %q %q, want Keynote 00:10:00", c.Label, c.Text)
+	}
+}
+
+func TestRundownSegmentColors(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.RGBA{A: 255}
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	engine, _ := newTestEngine(t, nil)
+	engine.SetSourceColors(0, white, black)
+	engine.LoadRundown([]Segment{
+		{Title: "Break", Duration: time.Minute, Counter: 1, Colors: []color.RGBA{red, blue}},
+		{Title: "Panel", Duration: time.Minute, Counter: 1},
+	})
+
+	steps := []struct {
+		name string
+		step func()
+		text color.RGBA
+		bg   color.RGBA
+	}{
+		{"segment with colors", engine.rundownGo, red, blue},
+		{"segment without colors", engine.rundownNext, white, black},
+		{"back to the colored segment", engine.rundownPrevious, red, blue},
+		{"rundown cleared", func() { engine.loadRundown(nil) }, white, black},
+	}
+	for _, s := range steps {
+		engine.mutex.Lock()
+		s.step()
+		engine.mutex.Unlock()
+		c := engine.State().Clocks[0]
+		if c.TextColor != s.text || c.BGColor != s.bg {
+			t.Errorf("%s: colors = %v / %v, want %v / %v", s.name, c.TextColor, c.BGColor, s.text, s.bg)
+		}
+		if engine.sources[0].colorOverride {
+			t.Errorf("%s: segment colors marked as an OSC override", s.name)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package clock
+
+import (
+	"fmt"
+	"github.com/stanchan/go-osc/osc"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Time-of-day scheduler for running engine commands
+ */
+
+// scheduleGrace is how late a scheduled command may still be run, entries missed
+// by more than this, eg. due to a system clock jump, are skipped until their next occurrence.
+const scheduleGrace = time.Minute
+
+// scheduleHorizon is the furthest an occurrence can be in the future
+const scheduleHorizon = 8 * 24 * time.Hour
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+type scheduleEntry struct {
+	id      int
+	spec    string // Entry as given
+	hour    int
+	minute  int
+	second  int
+	days    [7]bool // Indexed by time.Weekday
+	source  int     // Source whose time zone the time of day is in, starting from 1
+	command string
+	next    time.Time // Next occurrence
+}
+
+// ValidateScheduleEntry checks the syntax of a schedule entry in the form of
+// "HH:MM[:SS] days source command", eg. "19:00 mon-fri 1 /clock/timer/2/countdown/target 19:30:00".
+// Days is daily, weekdays, weekends or a comma separated list of day names and ranges.
+// The source number selects the time zone for the time of day.
+func ValidateScheduleEntry(spec string, sources int) error {
+	_, err := parseScheduleEntry(spec, sources)
+	return err
+}
+
+func parseScheduleEntry(spec string, sources int) (*scheduleEntry, error) {
+	fields := strings.Fields(spec)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("expected time, days, source and command: %q", spec)
+	}
+	e := scheduleEntry{spec: strings.TrimSpace(spec)}
+
+	parts := strings.Split(fields[0], ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("time not in HH:MM or HH:MM:SS format: %s", fields[0])
+	}
+	limits := []int{23, 59, 59}
+	values := []*int{&e.hour, &e.minute, &e.second}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || n > limits[i] {
+			return nil, fmt.Errorf("time not in HH:MM or HH:MM:SS format: %s", fields[0])
+		}
+		*values[i] = n
+	}
+
+	days, err := parseScheduleDays(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	e.days = days
+
+	e.source, err = strconv.Atoi(fields[2])
+	if err != nil || e.source < 1 {
+		return nil, fmt.Errorf("invalid source number: %s", fields[2])
+	} else if e.source > sources {
+		return nil, fmt.Errorf("source number %d out of range (have %d sources)", e.source, sources)
+	}
+
+	// Keep the command as written to preserve quoted arguments
+	e.command = strings.TrimSpace(e.spec)
+	for _, f := range fields[:3] {
+		e.command = strings.TrimSpace(strings.TrimPrefix(e.command, f))
+	}
+	if _, err := ParseCommand(e.command); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// parseScheduleDays parses daily, weekdays, weekends or a list like "mon,wed,fri-sun"
+func parseScheduleDays(s string) (days [7]bool, err error) {
+	switch strings.ToLower(s) {
+	case "daily", "*":
+		s = "sun-sat"
+	case "weekdays":
+		s = "mon-fri"
+	case "weekends":
+		s = "sat,sun"
+	}
+
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		r := strings.SplitN(part, "-", 2)
+		first, ok := weekdayNumber(r[0])
+		if !ok {
+			return days, fmt.Errorf("unknown day: %s", r[0])
+		}
+		last := first
+		if len(r) == 2 {
+			if last, ok = weekdayNumber(r[1]); !ok {
+				return days, fmt.Errorf("unknown day: %s", r[1])
+			}
+		}
+		// Ranges can wrap around the week, eg. fri-mon
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func weekdayNumber(name string) (int, bool) {
+	if len(name) < 3 {
+		return 0, false
+	}
+	for i, n := range weekdayNames {
+		if strings.HasPrefix(name, n) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// nextOccurrence finds the first scheduled time after t. The time of day is
+// resolved separately on each calendar date so that DST changes are handled
+// by time.Date instead of adding fixed 24 hour periods.
+func (e *scheduleEntry) nextOccurrence(t time.Time, tz *time.Location) time.Time {
+	local := t.In(tz)
+	for d := 0; d <= 7; d++ {
+		next := time.Date(local.Year(), local.Month(), local.Day()+d, e.hour, e.minute, e.second, 0, tz)
+		next = skipGap(next, e.hour, e.minute, e.second)
+		if next.After(t) && e.days[next.Weekday()] {
+			return next
+		}
+	}
+	// Not reached, all entries have at least one day
+	return t.Add(scheduleHorizon)
+}
+
+// skipGap moves a time of day that falls in a DST gap after the gap. time.Date
+// can resolve a skipped wall clock time to before the gap, eg. 02:30 to 01:30.
+func skipGap(t time.Time, hour, minute, second int) time.Time {
+	wall := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second
+	got := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if d := wall - got; d > 0 {
+		return t.Add(d)
+	}
+	return t
+}
+
+// AddSchedule adds a schedule entry
+func (engine *Engine) AddSchedule(spec string) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.addSchedule(spec)
+}
+
+func (engine *Engine) addSchedule(spec string) error {
+	e, err := parseScheduleEntry(spec, len(engine.sources))
+	if err != nil {
+		return err
+	}
+	msg, _ := ParseCommand(e.command)
+	if len(engine.commands.decode(msg)) == 0 {
+		return fmt.Errorf("unknown or invalid command: %s", e.command)
+	}
+
+	engine.scheduleID++
+	e.id = engine.scheduleID
+	e.next = e.nextOccurrence(engine.timeSource.Now(), engine.sources[e.source-1].tz)
+	engine.schedule = append(engine.schedule, e)
+	log.Printf("Schedule entry %d: %s, next run at %v", e.id, e.spec, e.next)
+	return nil
+}
+
+// RemoveSchedule removes the schedule entry with the given id
+func (engine *Engine) RemoveSchedule(id int) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.removeSchedule(id)
+}
+
+func (engine *Engine) removeSchedule(id int) error {
+	for i, e := range engine.schedule {
+		if e.id == id {
+			engine.schedule = append(engine.schedule[:i], engine.schedule[i+1:]...)
+			log.Printf("Removed schedule entry %d: %s", e.id, e.spec)
+			return nil
+		}
+	}
+	return fmt.Errorf("no schedule entry %d", id)
+}
+
+// checkSchedule runs the commands whose scheduled time has passed
+func (engine *Engine) checkSchedule(t time.Time) {
+	// Iterate over a copy, the commands can modify the schedule
+	for _, e := range append([]*scheduleEntry(nil), engine.schedule...) {
+		tz := engine.sources[e.source-1].tz
+		if t.Before(e.next) {
+			if e.next.Sub(t) > scheduleHorizon {
+				// The system clock has jumped backwards
+				e.next = e.nextOccurrence(t, tz)
+			}
+			continue
+		}
+
+		if t.Sub(e.next) <= scheduleGrace {
+			log.Printf("Running schedule entry %d: %s", e.id, e.command)
+			if err := engine.runCommand(e.command); err != nil {
+				log.Printf("Schedule entry %d: %v", e.id, err)
+			}
+		} else {
+			log.Printf("Skipping schedule entry %d missed at %v", e.id, e.next)
+		}
+		e.next = e.nextOccurrence(t, tz)
+	}
+}
+
+// sendSchedule sends the schedule entries as osc feedback
+func (engine *Engine) sendSchedule() {
+	if engine.oscDests == nil {
+		return
+	}
+
+	bundle := osc.NewBundle(time.Now())
+	bundle.Append(osc.NewMessage("/clock/schedule/list", engine.uuid, int32(len(engine.schedule))))
+	for _, e := range engine.schedule {
+		next := e.next.In(engine.sources[e.source-1].tz).Format("2006-01-02 15:04:05")
+		bundle.Append(osc.NewMessage("/clock/schedule/entry", engine.uuid, int32(e.id), e.spec, next))
+	}
+
+	data, err := bundle.MarshalBinary()
+	if err != nil {
+		log.Printf("Error sending schedule: %v", err)
+		return
+	}
+	engine.oscSendChan <- data
+}
@@ -0,0 +1,124 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"later today", "19:00 daily 1 /clock/hide", time.Date(2021, 3, 1, 12, 0, 0, 0, ny), time.Date(2021, 3, 1, 19, 0, 0, 0, ny)},
+		{"tomorrow", "09:00:30 daily 1 /clock/hide", time.Date(2021, 3, 1, 12, 0, 0, 0, ny), time.Date(2021, 3, 2, 9, 0, 30, 0, ny)},
+		{"friday to monday", "08:00 weekdays 1 /clock/hide", time.Date(2021, 3, 5, 9, 0, 0, 0, ny), time.Date(2021, 3, 8, 8, 0, 0, 0, ny)},
+		{"across spring forward", "19:00 daily 1 /clock/hide", time.Date(2021, 3, 13, 20, 0, 0, 0, ny), time.Date(2021, 3, 14, 19, 0, 0, 0, ny)},
+		{"skipped hour", "02:30 sun 1 /clock/hide", time.Date(2021, 3, 13, 12, 0, 0, 0, ny), time.Date(2021, 3, 14, 3, 30, 0, 0, ny)},
+		{"across fall back", "19:00 daily 1 /clock/hide", time.Date(2021, 11, 6, 20, 0, 0, 0, ny), time.Date(2021, 11, 7, 19, 0, 0, 0, ny)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parseScheduleEntry(tt.spec, 1)
+			if err != nil {
+				t.Fatalf("parseScheduleEntry: %v", err)
+			}
+			if got := e.nextOccurrence(tt.from, ny); !got.Equal(tt.want) {
+				t.Errorf("next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleGrace(t *testing.T) {
+	engine, fake := newTestEngine(t, nil)
+	if err := engine.AddSchedule("12:00:30 daily 1 /clock/timer/2/countup"); err != nil {
+		t.Fatalf("AddSchedule: %v", err)
+	}
+	if err := engine.AddSchedule("12:02 daily 1 /clock/timer/3/countup"); err != nil {
+		t.Fatalf("AddSchedule: %v", err)
+	}
+	check := func() {
+		engine.mutex.Lock()
+		engine.checkSchedule(fake.Now())
+		engine.mutex.Unlock()
+	}
+	active := func(counter int) bool {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
+		return engine.Counters[counter].active
+	}
+
+	// Within the grace period of the first entry
+	fake.Advance(50 * time.Second)
+	check()
+	if !active(2) {
+		t.Errorf("entry within the grace period was not run")
+	}
+
+	// The second entry is missed by more than the grace period
+	fake.Set(testStart.Add(5 * time.Minute))
+	check()
+	if active(3) {
+		t.Errorf("entry missed by %v was run", 3*time.Minute)
+	}
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	for _, e := range engine.schedule {
+		if want := testStart.AddDate(0, 0, 1); e.next.Before(want) {
+			t.Errorf("entry %d next run at %v, want the next day", e.id, e.next)
+		}
+	}
+}
+
+// TestScheduleWithoutOSC checks that the engine loop runs the schedule with OSC disabled
+func TestScheduleWithoutOSC(t *testing.T) {
+	engine, fake := newTestEngine(t, nil)
+	if err := engine.AddSchedule("12:00:01 daily 1 /clock/timer/2/countup"); err != nil {
+		t.Fatalf("AddSchedule: %v", err)
+	}
+	fake.Advance(2 * time.Second)
+
+	deadline := time.Now().Add(10 * checkTimer)
+	for time.Now().Before(deadline) {
+		engine.mutex.Lock()
+		active := engine.Counters[2].active
+		engine.mutex.Unlock()
+		if active {
+			return
+		}
+		time.Sleep(checkTimer / 2)
+	}
+	t.Errorf("schedule entry was not run with OSC disabled")
+}
+
+func TestScheduleTimezoneChange(t *testing.T) {
+	engine, fake := newTestEngine(t, nil)
+	if err := engine.AddSchedule("13:00 daily 1 /clock/timer/2/countup"); err != nil {
+		t.Fatalf("AddSchedule: %v", err)
+	}
+
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	if err := engine.setSourceTimezone(0, "Europe/Helsinki"); err != nil {
+		t.Fatalf("setSourceTimezone: %v", err)
+	}
+	// 13:00 in Helsinki is 11:00 UTC, already passed at the 12:00 UTC start
+	want := time.Date(2021, 3, 2, 11, 0, 0, 0, time.UTC)
+	if next := engine.schedule[0].next; !next.Equal(want) {
+		t.Errorf("next run after the zone change = %v, want %v", next.UTC(), want)
+	}
+
+	fake.Set(testStart.Add(time.Hour))
+	engine.checkSchedule(fake.Now())
+	if engine.Counters[2].active {
+		t.Errorf("entry ran at 13:00 UTC after the zone change")
+	}
+}
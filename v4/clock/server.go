@@ -7,13 +7,16 @@ import (
 	"log"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	timerPattern  = `/clock/timer/(\d)/`
-	sourcePattern = `/clock/source/([1-4])/`
+	timerPattern  = `/clock/timer/(\d+)/`
+	sourcePattern = `/clock/source/(\d+)/`
 	signalPattern = `/clock/signal/(\d)`
+	groupPattern  = `^/clock/group/([A-Za-z0-9_-]+)/`
 )
 
 // MakeServer creates a clock.Server instance from osc.Server instance
@@ -24,6 +27,7 @@ func MakeServer(oscServer *osc.Server, uuid string) *Server {
 		timerRegexp:  regexp.MustCompile(timerPattern),
 		sourceRegexp: regexp.MustCompile(sourcePattern),
 		signalRegexp: regexp.MustCompile(signalPattern),
+		groupRegexp:  regexp.MustCompile(groupPattern),
 		uuid:         uuid,
 	}
 
@@ -34,25 +38,45 @@ func MakeServer(oscServer *osc.Server, uuid string) *Server {
 
 // Server is a clock osc server and listens for incoming osc messages
 type Server struct {
+	listenerLock sync.Mutex
 	listeners    map[chan Message]struct{}
 	Debug        bool
 	timerRegexp  *regexp.Regexp
 	sourceRegexp *regexp.Regexp
 	signalRegexp *regexp.Regexp
+	groupRegexp  *regexp.Regexp
 	lastMedia    time.Time
 	uuid         string
+	handlers     []serverHandler // Registered handlers for decoding commands
+	decoding     bool            // Collect decoded messages instead of sending them to the listeners
+	decoded      []Message
+}
+
+// serverHandler is a osc handler with its compiled address pattern
+type serverHandler struct {
+	pattern *regexp.Regexp
+	handler osc.HandlerFunc
 }
 
 // Listen adds a new listener for the decoded incoming osc messages
 func (server *Server) Listen() chan Message {
 	var listenChan = make(chan Message)
+	server.listenerLock.Lock()
 	server.listeners[listenChan] = struct{}{}
+	server.listenerLock.Unlock()
 	return listenChan
 }
 
 func (server *Server) update(message Message) {
 	debug.Printf("update: %#v", message)
 
+	if server.decoding {
+		server.decoded = append(server.decoded, message)
+		return
+	}
+
+	server.listenerLock.Lock()
+	defer server.listenerLock.Unlock()
 	for listenChan := range server.listeners {
 		listenChan <- message
 	}
@@ -94,6 +118,70 @@ func (server *Server) handleTimerResume(msg *osc.Message) {
 	server.sendTimerCommand("timerResume", msg)
 }
 
+func (server *Server) handleTimerRate(msg *osc.Message) {
+	debug.Printf("handleTimerRate: %v", msg)
+	if matches := server.timerRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		counter, _ := strconv.Atoi(matches[1])
+		// Without arguments the counter returns to real time
+		rate := "1"
+		if msg.CountArguments() > 0 {
+			switch arg := msg.Arguments[0].(type) {
+			case float32:
+				rate = strconv.FormatFloat(float64(arg), 'f', -1, 32)
+			case int32:
+				rate = strconv.Itoa(int(arg))
+			default:
+				log.Printf("handleTimerRate: invalid argument type %T", arg)
+				return
+			}
+		}
+		m := Message{
+			Type:    "timerRate",
+			Counter: counter,
+			Data:    rate,
+		}
+		server.update(m)
+	}
+}
+
+func (server *Server) handleTimerLink(msg *osc.Message) {
+	debug.Printf("handleTimerLink: %v", msg)
+	if matches := server.timerRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		counter, _ := strconv.Atoi(matches[1])
+		// Without arguments the link is removed
+		var fields []string
+		for i, arg := range msg.Arguments {
+			switch a := arg.(type) {
+			case int32:
+				fields = append(fields, strconv.Itoa(int(a)))
+			case float32:
+				fields = append(fields, strconv.FormatFloat(float64(a), 'f', -1, 32))
+			case string:
+				fields = append(fields, a)
+			default:
+				log.Printf("handleTimerLink: invalid argument %d type %T", i, arg)
+				return
+			}
+		}
+		m := Message{
+			Type:    "timerLink",
+			Counter: counter,
+			Data:    strings.Join(fields, " "),
+		}
+		server.update(m)
+	}
+}
+
+func (server *Server) handleTimerUndo(msg *osc.Message) {
+	debug.Printf("handleTimerUndo: %v", msg)
+	server.sendTimerCommand("timerUndo", msg)
+}
+
+func (server *Server) handleTimerLap(msg *osc.Message) {
+	debug.Printf("handleTimerLap: %v", msg)
+	server.sendTimerCommand("timerLap", msg)
+}
+
 func (server *Server) handleCountdownTarget(msg *osc.Message) {
 	server.sendTargetMessage(msg, true)
 }
@@ -106,8 +194,15 @@ func (server *Server) sendTargetMessage(msg *osc.Message, countdown bool) {
 	debug.Printf("sendTargetMessage: %v %v", countdown, msg)
 	if matches := server.timerRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
 		counter, _ := strconv.Atoi(matches[1])
-		var target string
-		err := msg.UnmarshalArguments(&target)
+		var target, zone string
+		var err error
+		if msg.CountArguments() == 2 {
+			// Optional time zone name as a separate argument
+			err = msg.UnmarshalArguments(&target, &zone)
+			target += " " + zone
+		} else {
+			err = msg.UnmarshalArguments(&target)
+		}
 		if err != nil {
 			log.Printf("handleTimerTarget error: %v", err)
 			return
@@ -161,6 +256,123 @@ func (server *Server) sendTimerMessage(cmd string, countdown bool, msg *osc.Mess
 	}
 }
 
+func (server *Server) handleTimerOnExpire(msg *osc.Message) {
+	debug.Printf("handleTimerOnExpire: %v", msg)
+	if matches := server.timerRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		counter, _ := strconv.Atoi(matches[1])
+		var command string
+		err := msg.UnmarshalArguments(&command)
+		if err != nil {
+			log.Printf("handleTimerOnExpire error: %v", err)
+			return
+		}
+		m := Message{
+			Type:    "timerAction",
+			Counter: counter,
+			Data:    command,
+		}
+		server.update(m)
+	}
+}
+
+func (server *Server) handleTimerOnThreshold(msg *osc.Message) {
+	debug.Printf("handleTimerOnThreshold: %v", msg)
+	if matches := server.timerRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		counter, _ := strconv.Atoi(matches[1])
+		var seconds int32
+		var command string
+		err := msg.UnmarshalArguments(&seconds, &command)
+		if err != nil {
+			log.Printf("handleTimerOnThreshold error: %v", err)
+			return
+		}
+		if seconds < 1 {
+			log.Printf("handleTimerOnThreshold: invalid threshold %d", seconds)
+			return
+		}
+		m := Message{
+			Type:             "timerAction",
+			Counter:          counter,
+			Data:             command,
+			CountdownMessage: &CountdownMessage{Seconds: seconds},
+		}
+		server.update(m)
+	}
+}
+
+func (server *Server) handleTimerThresholds(msg *osc.Message) {
+	debug.Printf("handleTimerThresholds: %v", msg)
+	if matches := server.timerRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		counter, _ := strconv.Atoi(matches[1])
+		// Without arguments the profile is cleared
+		var profile string
+		if msg.CountArguments() > 0 {
+			if err := msg.UnmarshalArguments(&profile); err != nil {
+				log.Printf("handleTimerThresholds error: %v", err)
+				return
+			}
+		}
+		m := Message{
+			Type:    "timerThresholds",
+			Counter: counter,
+			Data:    profile,
+		}
+		server.update(m)
+	}
+}
+
+func (server *Server) handleTimerPreset(msg *osc.Message) {
+	debug.Printf("handleTimerPreset: %v", msg)
+	if matches := server.timerRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		counter, _ := strconv.Atoi(matches[1])
+		if msg.CountArguments() != 1 {
+			log.Printf("handleTimerPreset: expected preset name or number: %v", msg)
+			return
+		}
+		// Presets can be recalled by name or by number
+		var name string
+		switch arg := msg.Arguments[0].(type) {
+		case string:
+			name = arg
+		case int32:
+			name = strconv.Itoa(int(arg))
+		default:
+			log.Printf("handleTimerPreset: invalid argument type %T", arg)
+			return
+		}
+		m := Message{
+			Type:    "timerPreset",
+			Counter: counter,
+			Data:    name,
+		}
+		server.update(m)
+	}
+}
+
+func (server *Server) handlePresets(msg *osc.Message) {
+	debug.Printf("handlePresets: %v", msg)
+	server.update(Message{Type: "presetList"})
+}
+
+func (server *Server) handleTimerActionsClear(msg *osc.Message) {
+	debug.Printf("handleTimerActionsClear: %v", msg)
+	server.sendTimerCommand("timerActionsClear", msg)
+}
+
+func (server *Server) handleGroupCommand(msg *osc.Message) {
+	debug.Printf("handleGroupCommand: %v", msg)
+	if matches := server.groupRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		m := Message{
+			Type:    "groupCommand",
+			Data:    matches[1],
+			Command: msg,
+		}
+		server.update(m)
+	} else {
+		log.Printf("invalid group message: %v\n", msg)
+	}
+}
+
 func (server *Server) handlePause(msg *osc.Message) {
 	debug.Printf("pause: %#v", msg)
 	message := Message{
@@ -225,6 +437,99 @@ func (server *Server) parseSourceMsg(msg *osc.Message, cmd string) {
 	}
 }
 
+// parseSourceString sends a source message with a single string argument
+func (server *Server) parseSourceString(msg *osc.Message, cmd string) {
+	if matches := server.sourceRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		counter, _ := strconv.Atoi(matches[1])
+
+		var data string
+		if err := msg.UnmarshalArguments(&data); err != nil {
+			log.Printf("%s error: %v", cmd, err)
+			return
+		}
+
+		msg := Message{
+			Type:    cmd,
+			Counter: counter - 1,
+			Data:    data,
+		}
+		server.update(msg)
+	} else {
+		log.Printf("matches: %v", matches)
+		log.Printf("invalid source message: %v\n", msg)
+	}
+}
+
+func (server *Server) handleSourceTimezone(msg *osc.Message) {
+	debug.Printf("handleSourceTimezone: %v", msg)
+	server.parseSourceString(msg, "sourceTimezone")
+}
+
+func (server *Server) handleSourceCounter(msg *osc.Message) {
+	debug.Printf("handleSourceCounter: %v", msg)
+	if matches := server.sourceRegexp.FindStringSubmatch(msg.Address); len(matches) == 2 {
+		source, _ := strconv.Atoi(matches[1])
+
+		var counter int32
+		if err := msg.UnmarshalArguments(&counter); err != nil {
+			log.Printf("handleSourceCounter error: %v", err)
+			return
+		}
+
+		m := Message{
+			Type:    "sourceCounter",
+			Counter: source - 1,
+			Data:    strconv.Itoa(int(counter)),
+		}
+		server.update(m)
+	}
+}
+
+func (server *Server) handleSourceInputs(msg *osc.Message) {
+	debug.Printf("handleSourceInputs: %v", msg)
+	server.parseSourceString(msg, "sourceInputs")
+}
+
+func (server *Server) handleSourceOvertimeMode(msg *osc.Message) {
+	debug.Printf("handleSourceOvertimeMode: %v", msg)
+	server.parseSourceString(msg, "sourceOvertimeMode")
+}
+
+func (server *Server) handleSourceOvertimeVisibility(msg *osc.Message) {
+	debug.Printf("handleSourceOvertimeVisibility: %v", msg)
+	server.parseSourceString(msg, "sourceOvertimeVisibility")
+}
+
+func (server *Server) handleSource12h(msg *osc.Message) {
+	debug.Printf("handleSource12h: %v", msg)
+	server.parseSourceMsg(msg, "sourceFormat12h")
+}
+
+func (server *Server) handleSource24h(msg *osc.Message) {
+	debug.Printf("handleSource24h: %v", msg)
+	server.parseSourceMsg(msg, "sourceFormat24h")
+}
+
+func (server *Server) handleSourceSecondsOn(msg *osc.Message) {
+	debug.Printf("handleSourceSecondsOn: %v", msg)
+	server.parseSourceMsg(msg, "sourceSecondsOn")
+}
+
+func (server *Server) handleSourceSecondsOff(msg *osc.Message) {
+	debug.Printf("handleSourceSecondsOff: %v", msg)
+	server.parseSourceMsg(msg, "sourceSecondsOff")
+}
+
+func (server *Server) handleSourceBackTimeOn(msg *osc.Message) {
+	debug.Printf("handleSourceBackTimeOn: %v", msg)
+	server.parseSourceMsg(msg, "sourceBackTimeOn")
+}
+
+func (server *Server) handleSourceBackTimeOff(msg *osc.Message) {
+	debug.Printf("handleSourceBackTimeOff: %v", msg)
+	server.parseSourceMsg(msg, "sourceBackTimeOff")
+}
+
 func (server *Server) handleHideAll(msg *osc.Message) {
 	debug.Printf("handleHide: %#v", msg)
 
@@ -298,6 +603,11 @@ func (server *Server) handleSourceColor(msg *osc.Message) {
 	}
 }
 
+func (server *Server) handleSourceColorReset(msg *osc.Message) {
+	debug.Printf("handleSourceColorReset: %v", msg)
+	server.parseSourceMsg(msg, "sourceColorsReset")
+}
+
 func (server *Server) handleTitleColors(msg *osc.Message) {
 	debug.Printf("handleTitleColor: %v", msg)
 	cm := ColorMessage{}
@@ -582,58 +892,235 @@ func (server *Server) handleDisplay(msg *osc.Message) {
 	}
 }
 
+/*
+ * Rundown related handlers
+ */
+func (server *Server) handleRundownGo(msg *osc.Message) {
+	debug.Printf("handleRundownGo: %v", msg)
+	server.update(Message{Type: "rundownGo"})
+}
+
+func (server *Server) handleRundownNext(msg *osc.Message) {
+	debug.Printf("handleRundownNext: %v", msg)
+	server.update(Message{Type: "rundownNext"})
+}
+
+func (server *Server) handleRundownPrevious(msg *osc.Message) {
+	debug.Printf("handleRundownPrevious: %v", msg)
+	server.update(Message{Type: "rundownPrevious"})
+}
+
+func (server *Server) handleRundownJump(msg *osc.Message) {
+	debug.Printf("handleRundownJump: %v", msg)
+	var segment int32
+	err := msg.UnmarshalArguments(&segment)
+	if err != nil {
+		log.Printf("handleRundownJump error: %v", err)
+		return
+	}
+	m := Message{
+		Type:    "rundownJump",
+		Counter: int(segment),
+	}
+	server.update(m)
+}
+
+func (server *Server) handleRundownAdd(msg *osc.Message) {
+	debug.Printf("handleRundownAdd: %v", msg)
+	var title string
+	var seconds, counter int32
+	var autoAdvance bool
+	err := msg.UnmarshalArguments(&title, &seconds, &counter, &autoAdvance)
+	if err != nil {
+		log.Printf("handleRundownAdd error: %v", err)
+		return
+	}
+	m := Message{
+		Type: "rundownAdd",
+		Segment: &Segment{
+			Title:       title,
+			Duration:    time.Duration(seconds) * time.Second,
+			Counter:     int(counter),
+			AutoAdvance: autoAdvance,
+		},
+	}
+	server.update(m)
+}
+
+func (server *Server) handleRundownClear(msg *osc.Message) {
+	debug.Printf("handleRundownClear: %v", msg)
+	server.update(Message{Type: "rundownClear"})
+}
+
+func (server *Server) handleLogClear(msg *osc.Message) {
+	debug.Printf("handleLogClear: %v", msg)
+	server.update(Message{Type: "logClear"})
+}
+
+func (server *Server) handleShowHardOut(msg *osc.Message) {
+	debug.Printf("handleShowHardOut: %v", msg)
+	// Without arguments the hard out is cleared
+	var hardOut string
+	if msg.CountArguments() > 0 {
+		if err := msg.UnmarshalArguments(&hardOut); err != nil {
+			log.Printf("handleShowHardOut error: %v", err)
+			return
+		}
+	}
+	server.update(Message{Type: "showHardOut", Data: hardOut})
+}
+
+/*
+ * Scheduler related handlers
+ */
+func (server *Server) handleScheduleAdd(msg *osc.Message) {
+	debug.Printf("handleScheduleAdd: %v", msg)
+	var entry string
+	err := msg.UnmarshalArguments(&entry)
+	if err != nil {
+		log.Printf("handleScheduleAdd error: %v", err)
+		return
+	}
+	server.update(Message{Type: "scheduleAdd", Data: entry})
+}
+
+func (server *Server) handleScheduleRemove(msg *osc.Message) {
+	debug.Printf("handleScheduleRemove: %v", msg)
+	var id int32
+	err := msg.UnmarshalArguments(&id)
+	if err != nil {
+		log.Printf("handleScheduleRemove error: %v", err)
+		return
+	}
+	server.update(Message{Type: "scheduleRemove", Counter: int(id)})
+}
+
+func (server *Server) handleScheduleList(msg *osc.Message) {
+	debug.Printf("handleScheduleList: %v", msg)
+	server.update(Message{Type: "scheduleList"})
+}
+
+// decode runs the handlers matching the osc message address and returns
+// the resulting messages instead of sending them to the listeners
+func (server *Server) decode(msg *osc.Message) []Message {
+	var matched []osc.HandlerFunc
+	for _, h := range server.handlers {
+		if h.pattern.MatchString(msg.Address) {
+			matched = append(matched, h.handler)
+		}
+	}
+
+	server.decoding = true
+	server.decoded = nil
+	for _, handler := range matched {
+		handler(msg)
+	}
+	server.decoding = false
+	return server.decoded
+}
+
 // Le huge registerHandler block
 func (server *Server) setup(oscServer *osc.Server) {
 	// Sync messages
-	registerHandler(oscServer, "^/clock/media/*", server.handleMedia)
-	registerHandler(oscServer, "^/clock/resetmedia/*", server.handleResetMedia)
-	registerHandler(oscServer, "^/clock/ltc", server.handleLTC)
+	server.handle(oscServer, "^/clock/media/*", server.handleMedia)
+	server.handle(oscServer, "^/clock/resetmedia/*", server.handleResetMedia)
+	server.handle(oscServer, "^/clock/ltc", server.handleLTC)
 
 	// Timer related
-	registerHandler(oscServer, "^/clock/timer/*/countdown/target", server.handleCountdownTarget)
-	registerHandler(oscServer, "^/clock/timer/*/countdown$", server.handleCountdownStart)
-	registerHandler(oscServer, "^/clock/timer/*/countup/target", server.handleCountupTarget)
-	registerHandler(oscServer, "^/clock/timer/*/countup$", server.handleCountupStart)
-	registerHandler(oscServer, "^/clock/timer/*/modify", server.handleTimerModify)
-	registerHandler(oscServer, "^/clock/timer/*/signal", server.handleTimerSignal)
-	registerHandler(oscServer, "^/clock/timer/*/stop", server.handleTimerStop)
-	registerHandler(oscServer, "^/clock/timer/*/pause", server.handleTimerPause)
-	registerHandler(oscServer, "^/clock/timer/*/resume", server.handleTimerResume)
-	registerHandler(oscServer, "^/clock/pause", server.handlePause)
-	registerHandler(oscServer, "^/clock/resume", server.handleResume)
+	server.handle(oscServer, "^/clock/timer/*/countdown/target", server.handleCountdownTarget)
+	server.handle(oscServer, "^/clock/timer/*/countdown$", server.handleCountdownStart)
+	server.handle(oscServer, "^/clock/timer/*/countup/target", server.handleCountupTarget)
+	server.handle(oscServer, "^/clock/timer/*/countup$", server.handleCountupStart)
+	server.handle(oscServer, "^/clock/timer/*/modify", server.handleTimerModify)
+	server.handle(oscServer, "^/clock/timer/*/signal", server.handleTimerSignal)
+	server.handle(oscServer, "^/clock/timer/*/stop", server.handleTimerStop)
+	server.handle(oscServer, "^/clock/timer/*/pause", server.handleTimerPause)
+	server.handle(oscServer, "^/clock/timer/*/resume", server.handleTimerResume)
+	server.handle(oscServer, "^/clock/timer/*/onexpire", server.handleTimerOnExpire)
+	server.handle(oscServer, "^/clock/timer/*/onthreshold", server.handleTimerOnThreshold)
+	server.handle(oscServer, "^/clock/timer/*/actions/clear", server.handleTimerActionsClear)
+	server.handle(oscServer, "^/clock/timer/*/thresholds", server.handleTimerThresholds)
+	server.handle(oscServer, "^/clock/timer/*/lap", server.handleTimerLap)
+	server.handle(oscServer, "^/clock/timer/*/rate", server.handleTimerRate)
+	server.handle(oscServer, "^/clock/timer/*/undo", server.handleTimerUndo)
+	server.handle(oscServer, "^/clock/timer/*/link", server.handleTimerLink)
+	server.handle(oscServer, "^/clock/timer/*/preset", server.handleTimerPreset)
+	server.handle(oscServer, "^/clock/presets", server.handlePresets)
+	server.handle(oscServer, "^/clock/group/*", server.handleGroupCommand)
+	server.handle(oscServer, "^/clock/pause", server.handlePause)
+	server.handle(oscServer, "^/clock/resume", server.handleResume)
 
 	// Source related
-	registerHandler(oscServer, "^/clock/source/*/hide", server.handleHide)
-	registerHandler(oscServer, "^/clock/source/*/show", server.handleShow)
-	registerHandler(oscServer, "^/clock/source/*/title", server.handleSourceTitle)
-	registerHandler(oscServer, "^/clock/source/*/color", server.handleSourceColor)
-	registerHandler(oscServer, "^/clock/hide", server.handleHideAll)
-	registerHandler(oscServer, "^/clock/show", server.handleShowAll)
+	server.handle(oscServer, "^/clock/source/*/hide", server.handleHide)
+	server.handle(oscServer, "^/clock/source/*/show", server.handleShow)
+	server.handle(oscServer, "^/clock/source/*/title", server.handleSourceTitle)
+	server.handle(oscServer, "^/clock/source/*/colors?$", server.handleSourceColor)
+	server.handle(oscServer, "^/clock/source/*/colors/reset", server.handleSourceColorReset)
+	server.handle(oscServer, "^/clock/source/*/timezone", server.handleSourceTimezone)
+	server.handle(oscServer, "^/clock/source/*/counter", server.handleSourceCounter)
+	server.handle(oscServer, "^/clock/source/*/inputs", server.handleSourceInputs)
+	server.handle(oscServer, "^/clock/source/*/overtime/mode", server.handleSourceOvertimeMode)
+	server.handle(oscServer, "^/clock/source/*/overtime/visibility", server.handleSourceOvertimeVisibility)
+	server.handle(oscServer, "^/clock/source/*/format/12h", server.handleSource12h)
+	server.handle(oscServer, "^/clock/source/*/format/24h", server.handleSource24h)
+	server.handle(oscServer, "^/clock/source/*/seconds/on", server.handleSourceSecondsOn)
+	server.handle(oscServer, "^/clock/source/*/seconds/off", server.handleSourceSecondsOff)
+	server.handle(oscServer, "^/clock/source/*/backtime/on", server.handleSourceBackTimeOn)
+	server.handle(oscServer, "^/clock/source/*/backtime/off", server.handleSourceBackTimeOff)
+	server.handle(oscServer, "^/clock/hide", server.handleHideAll)
+	server.handle(oscServer, "^/clock/show$", server.handleShowAll)
+
+	// Rundown related
+	server.handle(oscServer, "^/clock/rundown/go", server.handleRundownGo)
+	server.handle(oscServer, "^/clock/show/hardout", server.handleShowHardOut)
+	server.handle(oscServer, "^/clock/log/clear", server.handleLogClear)
+	server.handle(oscServer, "^/clock/rundown/next", server.handleRundownNext)
+	server.handle(oscServer, "^/clock/rundown/previous", server.handleRundownPrevious)
+	server.handle(oscServer, "^/clock/rundown/jump", server.handleRundownJump)
+	server.handle(oscServer, "^/clock/rundown/add", server.handleRundownAdd)
+	server.handle(oscServer, "^/clock/rundown/clear", server.handleRundownClear)
+
+	// Scheduler related
+	server.handle(oscServer, "^/clock/schedule/add", server.handleScheduleAdd)
+	server.handle(oscServer, "^/clock/schedule/remove", server.handleScheduleRemove)
+	server.handle(oscServer, "^/clock/schedule/list", server.handleScheduleList)
 
 	// Misc commands
-	registerHandler(oscServer, "^/clock/background", server.handleBackground)
-	registerHandler(oscServer, "^/clock/info", server.handleInfo)
-	registerHandler(oscServer, "^/clock/text", server.handleDisplayText)
-	registerHandler(oscServer, "^/clock/titlecolors", server.handleTitleColors)
-	registerHandler(oscServer, "^/clock/seconds/off", server.handleSecondsOff)
-	registerHandler(oscServer, "^/clock/seconds/on", server.handleSecondsOn)
-	registerHandler(oscServer, "^/clock/time/set", server.handleTimeSet)
-	registerHandler(oscServer, "^/clock/flash", server.handleFlash)
-	registerHandler(oscServer, "^/clock/signal/*", server.handleHardwareSignal)
+	server.handle(oscServer, "^/clock/background", server.handleBackground)
+	server.handle(oscServer, "^/clock/info", server.handleInfo)
+	server.handle(oscServer, "^/clock/text", server.handleDisplayText)
+	server.handle(oscServer, "^/clock/titlecolors", server.handleTitleColors)
+	server.handle(oscServer, "^/clock/seconds/off", server.handleSecondsOff)
+	server.handle(oscServer, "^/clock/seconds/on", server.handleSecondsOn)
+	server.handle(oscServer, "^/clock/time/set", server.handleTimeSet)
+	server.handle(oscServer, "^/clock/flash", server.handleFlash)
+	server.handle(oscServer, "^/clock/signal/*", server.handleHardwareSignal)
 
 	// Deprecated
-	registerHandler(oscServer, "^/clock/dual/text", server.handleDualText)
-	registerHandler(oscServer, "^/clock/kill", server.handleHideAll)
-	registerHandler(oscServer, "^/clock/normal", server.handleShowAll)
-	registerHandler(oscServer, "^/clock/countup/start", server.handleCountupStart)
-	registerHandler(oscServer, "^/clock/countup/modify", server.handleTimerModify)
-	registerHandler(oscServer, "^/clock/display", server.handleDisplay)
-	registerHandler(oscServer, "^/clock/countdown/start", server.handleCountdownStart)
-	registerHandler(oscServer, "^/clock/countdown2/start", server.handleCountdownStart)
-	registerHandler(oscServer, "^/clock/countdown/modify", server.handleTimerModify)
-	registerHandler(oscServer, "^/clock/countdown2/modify", server.handleTimerModify)
-	registerHandler(oscServer, "^/clock/countdown/stop", server.handleTimerStop)
-	registerHandler(oscServer, "^/clock/countdown2/stop", server.handleTimerStop)
+	server.handle(oscServer, "^/clock/dual/text", server.handleDualText)
+	server.handle(oscServer, "^/clock/kill", server.handleHideAll)
+	server.handle(oscServer, "^/clock/normal", server.handleShowAll)
+	server.handle(oscServer, "^/clock/countup/start", server.handleCountupStart)
+	server.handle(oscServer, "^/clock/countup/modify", server.handleTimerModify)
+	server.handle(oscServer, "^/clock/display", server.handleDisplay)
+	server.handle(oscServer, "^/clock/countdown/start", server.handleCountdownStart)
+	server.handle(oscServer, "^/clock/countdown2/start", server.handleCountdownStart)
+	server.handle(oscServer, "^/clock/countdown/modify", server.handleTimerModify)
+	server.handle(oscServer, "^/clock/countdown2/modify", server.handleTimerModify)
+	server.handle(oscServer, "^/clock/countdown/stop", server.handleTimerStop)
+	server.handle(oscServer, "^/clock/countdown2/stop", server.handleTimerStop)
+}
+
+// handle registers a handler on the osc server, if any, and keeps it for decoding commands
+func (server *Server) handle(oscServer *osc.Server, addr string, handler osc.HandlerFunc) {
+	if oscServer != nil {
+		registerHandler(oscServer, addr, handler)
+	}
+	pattern := strings.NewReplacer(".", `\.`, "*", ".*").Replace(addr)
+	server.handlers = append(server.handlers, serverHandler{
+		pattern: regexp.MustCompile(pattern),
+		handler: handler,
+	})
 }
 
 func registerHandler(server *osc.Server, addr string, handler osc.HandlerFunc) {
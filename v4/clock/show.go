@@ -0,0 +1,129 @@
+package clock
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+/*
+ * Show hard out and over/under tracking against the rundown
+ */
+
+// ShowState is the show timing at the time State() was called
+type ShowState struct {
+	Active    bool          // True if a hard out is set and the rundown has segments
+	HardOut   time.Time     // Time the show must end by, in the time zone of source 1
+	Projected time.Time     // Projected end of the rundown, in the time zone of source 1
+	OverUnder time.Duration // Projected end compared to the hard out, positive when running over
+}
+
+// ValidateHardOut checks the syntax of a show hard out time. It is a time of day
+// as HH:MM:SS or an ISO 8601 date-time with an optional time zone name.
+func ValidateHardOut(hardOut string) error {
+	if hardOut == "" {
+		return nil
+	}
+	_, err := parseTarget(hardOut, time.Now(), time.UTC, true)
+	return err
+}
+
+// SetHardOut sets the time of day the show must end by, an empty string clears it
+func (engine *Engine) SetHardOut(hardOut string) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.setHardOut(hardOut)
+}
+
+func (engine *Engine) setHardOut(hardOut string) error {
+	if hardOut == "" {
+		engine.hardOut = ""
+		log.Printf("Show hard out cleared")
+		return nil
+	}
+	if _, err := parseTarget(hardOut, engine.timeSource.Now(), engine.sources[0].tz, true); err != nil {
+		return fmt.Errorf("hard out: %v", err)
+	}
+	engine.hardOut = hardOut
+	log.Printf("Show hard out set to %s", hardOut)
+	return nil
+}
+
+// resolveHardOut turns the hard out into a time for a rundown projected to end at
+// the given time. A time of day is taken on the day nearest to the end, so a show
+// running past it is over instead of being under until the next day, and long runs
+// follow the current day. Date-times are used as is.
+func (engine *Engine) resolveHardOut(end time.Time) time.Time {
+	tz := engine.sources[0].tz
+	next, err := parseTarget(engine.hardOut, end, tz, true)
+	if err != nil {
+		return time.Time{}
+	}
+	prev, _ := parseTarget(engine.hardOut, end, tz, false)
+	if end.Sub(prev) <= next.Sub(end) {
+		return prev
+	}
+	return next
+}
+
+// projectedEnd calculates when the rundown will end if the current segment runs
+// to its target and the remaining segments take their planned time
+func (engine *Engine) projectedEnd(t time.Time) time.Time {
+	end := t
+	next := 0
+	if s := engine.currentSegment(); s != nil {
+		counter := engine.Counters[s.Counter]
+		if counter.active && counter.countdown {
+			// Paused and modified segments are taken into account through the counter
+			if left := counter.Diff(t); left > 0 {
+				end = end.Add(counter.unscale(left))
+			}
+		}
+		next = engine.rundown.current
+	}
+
+	for _, s := range engine.rundown.segments[next:] {
+		if s.Target != "" {
+			// Segments running to a hard start can't end before it
+			if target, err := parseTarget(s.Target, t, engine.sources[0].tz, true); err == nil && target.After(end) {
+				end = target
+			}
+		} else {
+			end = end.Add(s.Duration)
+		}
+	}
+	return end
+}
+
+func (engine *Engine) showState(t time.Time) ShowState {
+	if engine.hardOut == "" || len(engine.rundown.segments) == 0 {
+		return ShowState{}
+	}
+	end := engine.projectedEnd(t)
+	hardOut := engine.resolveHardOut(end)
+	if hardOut.IsZero() {
+		return ShowState{}
+	}
+	tz := engine.sources[0].tz
+	return ShowState{
+		Active:    true,
+		HardOut:   hardOut.In(tz),
+		Projected: end.In(tz),
+		OverUnder: end.Sub(hardOut),
+	}
+}
+
+// overUnderState fills the clock with the signed show over/under time
+func (engine *Engine) overUnderState(c *Clock, show ShowState) {
+	d := show.OverUnder.Round(time.Second)
+	c.Mode = OverUnder
+	c.Icon = "+"
+	if d < 0 {
+		c.Icon = "-"
+		d = -d
+	}
+	c.Hours, c.Minutes, c.Seconds = splatDuration(d)
+	c.Days = c.Hours / 24
+	c.Text = formatDuration(d)
+	c.Compact = fmt.Sprintf("%s%s", c.Icon, secsToCompact(int64(d.Seconds())))
+}
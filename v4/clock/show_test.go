@@ -0,0 +1,72 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShowHardOut(t *testing.T) {
+	tests := []struct {
+		name      string
+		hardOut   string
+		advance   time.Duration // Time passed after setting the hard out
+		hardOutAt time.Time
+		overUnder time.Duration
+	}{
+		{
+			name:      "under",
+			hardOut:   "13:00:00",
+			hardOutAt: time.Date(2021, 3, 1, 13, 0, 0, 0, time.UTC),
+			overUnder: -30 * time.Minute,
+		},
+		{
+			name:      "passed hard out is over",
+			hardOut:   "11:00:00",
+			hardOutAt: time.Date(2021, 3, 1, 11, 0, 0, 0, time.UTC),
+			overUnder: 90 * time.Minute,
+		},
+		{
+			name:      "follows the day on long runs",
+			hardOut:   "13:00:00",
+			advance:   48 * time.Hour,
+			hardOutAt: time.Date(2021, 3, 3, 13, 0, 0, 0, time.UTC),
+			overUnder: -30 * time.Minute,
+		},
+		{
+			name:      "date-time",
+			hardOut:   "2021-03-02T12:00:00",
+			hardOutAt: time.Date(2021, 3, 2, 12, 0, 0, 0, time.UTC),
+			overUnder: -(23*time.Hour + 30*time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, fake := newTestEngine(t, nil)
+			engine.LoadRundown([]Segment{
+				{Title: "Opening", Duration: 10 * time.Minute, Counter: 1},
+				{Title: "Keynote", Duration: 20 * time.Minute, Counter: 1},
+			})
+			if err := engine.SetHardOut(tt.hardOut); err != nil {
+				t.Fatalf("SetHardOut: %v", err)
+			}
+			fake.Advance(tt.advance)
+
+			show := engine.State().Show
+			if !show.Active {
+				t.Fatalf("show state not active")
+			}
+			if !show.HardOut.Equal(tt.hardOutAt) {
+				t.Errorf("hard out = %v, want %v", show.HardOut, tt.hardOutAt)
+			}
+			if show.OverUnder != tt.overUnder {
+				t.Errorf("over/under = %v, want %v", show.OverUnder, tt.overUnder)
+			}
+		})
+	}
+
+	engine, _ := newTestEngine(t, nil)
+	if err := engine.SetHardOut("25:00"); err == nil {
+		t.Errorf("SetHardOut accepted an invalid time")
+	}
+}
@@ -0,0 +1,74 @@
+package clock
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Counter target parsing
+ */
+
+var targetTimeRegexp = regexp.MustCompile(`^([0-1]?[0-9]|2[0-3]):([0-5][0-9]):([0-5][0-9])$`)
+
+// Accepted ISO 8601 date and date-time layouts, the offset is optional
+var targetLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTarget resolves a counter target string to a point in time. The target is
+// either a time of day as HH:MM:SS or an ISO 8601 date or date-time, optionally
+// followed by a time zone name, eg. "2021-12-24T18:00:00 America/New_York".
+// Without a zone name or offset the target is in the given time zone.
+// A time of day resolves to its next occurrence for countdowns and to its
+// previous occurrence for count ups. The day is changed on the calendar so
+// that the result is correct across DST changes.
+func parseTarget(target string, now time.Time, tz *time.Location, countdown bool) (time.Time, error) {
+	fields := strings.Fields(target)
+	if len(fields) == 0 {
+		return time.Time{}, fmt.Errorf("empty target")
+	}
+	if len(fields) > 1 && !strings.Contains(fields[len(fields)-1], ":") {
+		loc, err := time.LoadLocation(fields[len(fields)-1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone: %s", fields[len(fields)-1])
+		}
+		tz = loc
+		fields = fields[:len(fields)-1]
+	}
+	target = strings.Join(fields, " ")
+
+	if m := targetTimeRegexp.FindStringSubmatch(target); m != nil {
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		seconds, _ := strconv.Atoi(m[3])
+
+		local := now.In(tz)
+		day := func(offset int) time.Time {
+			return skipGap(time.Date(local.Year(), local.Month(), local.Day()+offset, hours, minutes, seconds, 0, tz), hours, minutes, seconds)
+		}
+		t := day(0)
+		if countdown && t.Before(now) {
+			t = day(1)
+		} else if !countdown && t.After(now) {
+			t = day(-1)
+		}
+		return t, nil
+	}
+
+	for _, layout := range targetLayouts {
+		if t, err := time.ParseInLocation(layout, target, tz); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected HH:MM:SS or a ISO 8601 date-time: %s", target)
+}
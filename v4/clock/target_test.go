@@ -0,0 +1,75 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTarget(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	noon := time.Date(2021, 3, 13, 12, 0, 0, 0, ny)
+	evening := time.Date(2021, 3, 13, 20, 0, 0, 0, ny) // The day before DST starts
+
+	tests := []struct {
+		name      string
+		target    string
+		now       time.Time
+		countdown bool
+		want      time.Time
+	}{
+		{"later today", "18:00:00", noon, true, time.Date(2021, 3, 13, 18, 0, 0, 0, ny)},
+		{"tomorrow", "09:00:00", noon, true, time.Date(2021, 3, 14, 9, 0, 0, 0, ny)},
+		{"count up from yesterday", "18:00:00", noon, false, time.Date(2021, 3, 12, 18, 0, 0, 0, ny)},
+		{"count up from today", "09:00:00", noon, false, time.Date(2021, 3, 13, 9, 0, 0, 0, ny)},
+		{"across spring forward", "19:00:00", evening, true, time.Date(2021, 3, 14, 19, 0, 0, 0, ny)},
+		{"skipped hour", "02:30:00", evening, true, time.Date(2021, 3, 14, 3, 30, 0, 0, ny)},
+		{"across fall back", "19:00:00", time.Date(2021, 11, 6, 20, 0, 0, 0, ny), true, time.Date(2021, 11, 7, 19, 0, 0, 0, ny)},
+		{"time of day in a named zone", "18:00:00 Europe/Helsinki", noon, true, time.Date(2021, 3, 14, 18, 0, 0, 0, helsinki)},
+		{"date-time", "2021-12-24T18:00:00", noon, true, time.Date(2021, 12, 24, 18, 0, 0, 0, ny)},
+		{"date-time with offset", "2021-12-24T18:00:00Z", noon, true, time.Date(2021, 12, 24, 18, 0, 0, 0, time.UTC)},
+		{"date-time in a named zone", "2021-12-24 18:00 Europe/Helsinki", noon, true, time.Date(2021, 12, 24, 18, 0, 0, 0, helsinki)},
+		{"date", "2021-12-24", noon, true, time.Date(2021, 12, 24, 0, 0, 0, 0, ny)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTarget(tt.target, tt.now, ny, tt.countdown)
+			if err != nil {
+				t.Fatalf("parseTarget(%q): %v", tt.target, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTarget(%q) = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+
+	for _, target := range []string{"", "24:00:00", "18:00", "18:00:00 Mars/Olympus_Mons", "24.12.2021"} {
+		if _, err := parseTarget(target, noon, ny, true); err == nil {
+			t.Errorf("parseTarget(%q) did not return an error", target)
+		}
+	}
+}
+
+// TestTargetCountdownAcrossDST checks that a countdown to a date-time counts
+// real elapsed time over the DST change
+func TestTargetCountdownAcrossDST(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.Sources[0].TimeZone = "America/New_York"
+	})
+	ny := engine.sources[0].tz
+	fake.Set(time.Date(2021, 3, 13, 20, 0, 0, 0, ny))
+
+	engine.TargetCounter(1, "2021-03-14T20:00:00", true)
+	engine.mutex.Lock()
+	left := engine.Counters[1].Diff(fake.Now())
+	engine.mutex.Unlock()
+	if left != 23*time.Hour {
+		t.Errorf("time left = %v, want 23h0m0s", left)
+	}
+}
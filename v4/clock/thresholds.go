@@ -0,0 +1,187 @@
+package clock
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Per-counter signal threshold profiles
+ */
+
+// thresholdStep is a single step in a threshold profile
+type thresholdStep struct {
+	threshold time.Duration // Time left on the countdown, used if percent is zero
+	percent   float64       // Threshold as percentage of the countdown duration
+	color     color.RGBA
+	blinkOn   time.Duration // Blink pattern, no blinking if zero
+	blinkOff  time.Duration
+}
+
+// thresholdProfile is a list of signal color steps for a counter
+type thresholdProfile []thresholdStep
+
+// ValidateThresholds checks the syntax of a counter threshold profile in the form
+// of "counter steps", where the steps are separated by commas, eg.
+// "1 300 #FFFF00, 10% #FF8000, 60 #FF0000 250/250".
+// Each step has a threshold as seconds left or as a percentage of the countdown
+// duration, a color and an optional blink pattern as on/off milliseconds.
+func ValidateThresholds(spec string, counters int) error {
+	_, _, err := parseCounterThresholds(spec, counters)
+	return err
+}
+
+func parseCounterThresholds(spec string, counters int) (int, thresholdProfile, error) {
+	parts := strings.SplitN(strings.TrimSpace(spec), " ", 2)
+	if len(parts) < 2 {
+		return 0, nil, fmt.Errorf("expected counter and threshold steps: %q", spec)
+	}
+	counter, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid counter number: %s", parts[0])
+	} else if counter < 0 || counter >= counters {
+		return 0, nil, fmt.Errorf("counter number %d out of range (have %d counters)", counter, counters)
+	}
+	profile, err := parseThresholds(parts[1])
+	return counter, profile, err
+}
+
+// parseThresholds parses the comma separated threshold steps
+func parseThresholds(spec string) (thresholdProfile, error) {
+	var profile thresholdProfile
+	for _, s := range strings.Split(spec, ",") {
+		fields := strings.Fields(s)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("expected threshold, color and optional blink pattern: %q", strings.TrimSpace(s))
+		}
+
+		step := thresholdStep{color: color.RGBA{A: 255}}
+		if strings.HasSuffix(fields[0], "%") {
+			p, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+			if err != nil || p <= 0 || p > 100 {
+				return nil, fmt.Errorf("invalid threshold percentage: %s", fields[0])
+			}
+			step.percent = p
+		} else {
+			seconds, err := strconv.Atoi(fields[0])
+			if err != nil || seconds < 0 {
+				return nil, fmt.Errorf("invalid threshold seconds: %s", fields[0])
+			}
+			step.threshold = time.Duration(seconds) * time.Second
+		}
+
+		if _, err := fmt.Sscanf(fields[1], "#%02x%02x%02x", &step.color.R, &step.color.G, &step.color.B); err != nil || len(fields[1]) != 7 {
+			return nil, fmt.Errorf("invalid color, expected #RRGGBB: %s", fields[1])
+		}
+
+		if len(fields) == 3 {
+			var on, off int
+			if _, err := fmt.Sscanf(fields[2], "%d/%d", &on, &off); err != nil || on <= 0 || off <= 0 {
+				return nil, fmt.Errorf("invalid blink pattern, expected on/off milliseconds: %s", fields[2])
+			}
+			step.blinkOn = time.Duration(on) * time.Millisecond
+			step.blinkOff = time.Duration(off) * time.Millisecond
+		}
+		profile = append(profile, step)
+	}
+	return profile, nil
+}
+
+// at returns the threshold of the step for a countdown of the given duration
+func (step *thresholdStep) at(duration time.Duration) time.Duration {
+	if step.percent != 0 {
+		return time.Duration(float64(duration) * step.percent / 100)
+	}
+	return step.threshold
+}
+
+// active returns the index of the step with the lowest threshold the countdown
+// has crossed, or -1 if the countdown is above all thresholds
+func (profile thresholdProfile) active(diff, duration time.Duration) int {
+	active := -1
+	for i := range profile {
+		threshold := profile[i].at(duration)
+		if diff < threshold && (active < 0 || threshold < profile[active].at(duration)) {
+			active = i
+		}
+	}
+	return active
+}
+
+// blinkOffPhase tells if a blinking step is in the off phase of its pattern
+func (step *thresholdStep) blinkOffPhase(t time.Time) bool {
+	if step.blinkOn == 0 {
+		return false
+	}
+	period := step.blinkOn + step.blinkOff
+	return time.Duration(t.UnixNano())%period >= step.blinkOn
+}
+
+// SetThresholds sets the signal threshold profile for a counter, an empty profile
+// returns the counter to the global signal thresholds
+func (engine *Engine) SetThresholds(counter int, profile string) error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return engine.setThresholds(counter, profile)
+}
+
+func (engine *Engine) setThresholds(counter int, profile string) error {
+	if counter < 0 || counter >= len(engine.Counters) {
+		return fmt.Errorf("counter number %d out of range (have %d counters)", counter, len(engine.Counters))
+	}
+	if strings.TrimSpace(profile) == "" {
+		engine.Counters[counter].thresholds = nil
+		return nil
+	}
+	p, err := parseThresholds(profile)
+	if err != nil {
+		return err
+	}
+	engine.Counters[counter].thresholds = p
+	// Force the signal color update on the next state refresh
+	engine.Counters[counter].autoColorState = -1
+	return nil
+}
+
+// loadThresholds sets the counter threshold profiles from the configuration
+func (engine *Engine) loadThresholds(specs []string) error {
+	for _, spec := range specs {
+		counter, profile, err := parseCounterThresholds(spec, len(engine.Counters))
+		if err != nil {
+			return fmt.Errorf("timer thresholds %q: %v", spec, err)
+		}
+		engine.Counters[counter].thresholds = profile
+		engine.defaultThresholds[counter] = profile
+		log.Printf("Counter %d signal thresholds: %s", counter, strings.TrimSpace(strings.SplitN(strings.TrimSpace(spec), " ", 2)[1]))
+	}
+	return nil
+}
+
+// thresholdSignal sets the counter signal color from its threshold profile
+func (engine *Engine) thresholdSignal(counter *Counter, out *CounterOutput, t time.Time) {
+	off := color.RGBA{R: 0, G: 0, B: 0, A: 0}
+	i := -1
+	if out.Countdown {
+		i = counter.thresholds.active(out.Diff, counter.state.duration)
+	}
+
+	if i < 0 {
+		if engine.signalStart {
+			counter.setAutoColor(engine.signalColors[colorStart], autoColorStart)
+		} else {
+			counter.setAutoColor(off, autoColorOff)
+		}
+		return
+	}
+
+	step := &counter.thresholds[i]
+	if step.blinkOffPhase(t) {
+		counter.setAutoColor(off, autoColorStep+2*i+1)
+	} else {
+		counter.setAutoColor(step.color, autoColorStep+2*i)
+	}
+}
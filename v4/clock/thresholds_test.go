@@ -0,0 +1,97 @@
+package clock
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestParseThresholds(t *testing.T) {
+	profile, err := parseThresholds("300 #FFFF00, 10% #FF8000, 60 #FF0000 250/750")
+	if err != nil {
+		t.Fatalf("parseThresholds: %v", err)
+	}
+	want := thresholdProfile{
+		{threshold: 300 * time.Second, color: color.RGBA{R: 255, G: 255, A: 255}},
+		{percent: 10, color: color.RGBA{R: 255, G: 128, A: 255}},
+		{threshold: time.Minute, color: color.RGBA{R: 255, A: 255}, blinkOn: 250 * time.Millisecond, blinkOff: 750 * time.Millisecond},
+	}
+	if len(profile) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(profile), len(want))
+	}
+	for i := range want {
+		if profile[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i, profile[i], want[i])
+		}
+	}
+
+	for _, spec := range []string{"", "300", "300 #FFFF00 250", "0% #FFFF00", "101% #FFFF00", "-1 #FFFF00", "300 yellow", "300 #FFFF00 0/250"} {
+		if _, err := parseThresholds(spec); err == nil {
+			t.Errorf("parseThresholds(%q) did not return an error", spec)
+		}
+	}
+}
+
+func TestThresholdActive(t *testing.T) {
+	profile, err := parseThresholds("300 #FFFF00, 10% #FF8000, 60 #FF0000")
+	if err != nil {
+		t.Fatalf("parseThresholds: %v", err)
+	}
+
+	tests := []struct {
+		diff     time.Duration
+		duration time.Duration
+		want     int
+	}{
+		{10 * time.Minute, 10 * time.Minute, -1},
+		{299 * time.Second, 10 * time.Minute, 0},
+		{59 * time.Second, 10 * time.Minute, 1}, // 10% is 60s, the first of the equal steps wins
+		{30 * time.Second, 10 * time.Minute, 1},
+		{330 * time.Second, time.Hour, 1}, // 10% is 6 minutes
+		{299 * time.Second, time.Hour, 0}, // The 300s step is lower than 10%
+		{59 * time.Second, time.Hour, 2},
+		{90 * time.Second, 20 * time.Minute, 1},
+		{0, 20 * time.Minute, 2},
+	}
+	for _, tt := range tests {
+		if got := profile.active(tt.diff, tt.duration); got != tt.want {
+			t.Errorf("active(%v, %v) = %d, want %d", tt.diff, tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestThresholdSignal(t *testing.T) {
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.Thresholds = []string{"1 50% #FFFF00, 60 #FF0000 500/500"}
+		o.AutoSignals = true
+	})
+	yellow := color.RGBA{R: 255, G: 255, A: 255}
+	red := color.RGBA{R: 255, A: 255}
+	off := color.RGBA{}
+
+	engine.StartCounter(1, true, 4*time.Minute)
+	steps := []struct {
+		advance time.Duration
+		want    color.RGBA
+	}{
+		{0, off},
+		{2*time.Minute + time.Second, yellow},
+		{time.Minute, red},
+		{500 * time.Millisecond, off}, // Blink off phase
+		{500 * time.Millisecond, red},
+	}
+	for _, s := range steps {
+		fake.Advance(s.advance)
+		if c := engine.State().Clocks[0]; c.SignalColor != s.want {
+			t.Errorf("%s left: signal color = %v, want %v", c.Text, c.SignalColor, s.want)
+		}
+	}
+
+	// An empty profile returns the counter to the global thresholds
+	if err := engine.SetThresholds(1, ""); err != nil {
+		t.Fatalf("SetThresholds: %v", err)
+	}
+	if c := engine.State().Clocks[0]; c.SignalColor != red {
+		t.Errorf("global end threshold: signal color = %v, want %v", c.SignalColor, red)
+	}
+}
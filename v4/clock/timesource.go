@@ -0,0 +1,50 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// TimeSource provides the current time for the engine and its counters
+type TimeSource interface {
+	Now() time.Time
+}
+
+// systemTime is the default TimeSource using the system clock
+type systemTime struct{}
+
+func (systemTime) Now() time.Time {
+	return time.Now()
+}
+
+// FakeTime is a TimeSource that only moves when told to, for driving the engine deterministically
+type FakeTime struct {
+	mutex sync.Mutex
+	t     time.Time
+}
+
+// NewFakeTime creates a FakeTime starting at the given time
+func NewFakeTime(t time.Time) *FakeTime {
+	return &FakeTime{t: t}
+}
+
+// Now returns the current fake time
+func (fake *FakeTime) Now() time.Time {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	return fake.t
+}
+
+// Set jumps the fake time to the given time
+func (fake *FakeTime) Set(t time.Time) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.t = t
+}
+
+// Advance moves the fake time forward by the given duration
+func (fake *FakeTime) Advance(d time.Duration) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.t = fake.t.Add(d)
+}
@@ -0,0 +1,145 @@
+package clock
+
+import (
+	"sort"
+	"time"
+)
+
+/*
+ * Timing log of counter events for post-show reports
+ */
+
+// maxTimingLog limits the number of events kept in the timing log, the oldest are dropped first
+const maxTimingLog = 10000
+
+// SegmentTiming is the planned and actual duration of a single run of a rundown segment
+type SegmentTiming struct {
+	Segment int       `json:"segment"` // Segment number starting from 1
+	Title   string    `json:"title"`   // Segment title
+	Counter int       `json:"counter"` // Counter the segment ran on
+	Start   time.Time `json:"start"`   // Time the segment was started
+	End     time.Time `json:"end"`     // Time the segment ended, zero if it is still running
+	Running bool      `json:"running"` // Is the segment still running
+	Planned int       `json:"planned"` // Planned duration in seconds
+	Actual  int       `json:"actual"`  // Actual running time in seconds, without pauses
+	Paused  int       `json:"paused"`  // Time spent paused in seconds
+	Overrun int       `json:"overrun"` // Actual compared to planned in seconds, negative if the segment ended early
+	Expired bool      `json:"expired"` // Did the countdown reach zero
+}
+
+// logEvent adds an event to the timing log. Threshold events aren't logged.
+func (engine *Engine) logEvent(e Event) {
+	if e.Type == EventWarning || e.Type == EventEnd {
+		return
+	}
+	if len(engine.timingLog) >= maxTimingLog {
+		engine.timingLog = engine.timingLog[1:]
+	}
+	engine.timingLog = append(engine.timingLog, e)
+}
+
+// TimingLog returns a copy of the logged counter events in order
+func (engine *Engine) TimingLog() []Event {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return append([]Event(nil), engine.timingLog...)
+}
+
+// ClearTimingLog removes all events from the timing log
+func (engine *Engine) ClearTimingLog() {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	engine.timingLog = nil
+}
+
+// SegmentReport calculates the planned and actual durations of the rundown
+// segments from the timing log. Segments that are still running are
+// calculated up to the current time.
+func (engine *Engine) SegmentReport() []SegmentTiming {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+	return segmentReport(engine.timingLog, engine.timeSource.Now())
+}
+
+// segmentRun tracks a segment while going through the timing log
+type segmentRun struct {
+	timing   SegmentTiming
+	start    time.Time
+	pausedAt time.Time // Start of the current pause, zero if running
+	paused   time.Duration
+}
+
+func (run *segmentRun) finish(t time.Time, running bool) SegmentTiming {
+	if !run.pausedAt.IsZero() {
+		run.paused += t.Sub(run.pausedAt)
+	}
+	timing := run.timing
+	timing.Running = running
+	if !running {
+		timing.End = t
+	}
+	actual := t.Sub(run.start) - run.paused
+	timing.Actual = int(actual.Round(time.Second).Seconds())
+	timing.Paused = int(run.paused.Round(time.Second).Seconds())
+	timing.Overrun = timing.Actual - timing.Planned
+	return timing
+}
+
+func segmentReport(events []Event, now time.Time) []SegmentTiming {
+	var report []SegmentTiming
+	runs := make(map[int]*segmentRun) // Open segment runs by counter
+
+	end := func(counter int, t time.Time) {
+		if run, ok := runs[counter]; ok {
+			report = append(report, run.finish(t, false))
+			delete(runs, counter)
+		}
+	}
+
+	for _, e := range events {
+		switch e.Type {
+		case EventStart:
+			end(e.Counter, e.Time)
+			if e.Segment > 0 {
+				runs[e.Counter] = &segmentRun{
+					timing: SegmentTiming{
+						Segment: e.Segment,
+						Title:   e.Title,
+						Counter: e.Counter,
+						Start:   e.Time,
+						Planned: e.Duration,
+					},
+					start: e.Time,
+				}
+			}
+		case EventStop:
+			end(e.Counter, e.Time)
+		case EventPause:
+			if run, ok := runs[e.Counter]; ok && run.pausedAt.IsZero() {
+				run.pausedAt = e.Time
+			}
+		case EventResume:
+			if run, ok := runs[e.Counter]; ok && !run.pausedAt.IsZero() {
+				run.paused += e.Time.Sub(run.pausedAt)
+				run.pausedAt = time.Time{}
+			}
+		case EventExpire:
+			if run, ok := runs[e.Counter]; ok {
+				run.timing.Expired = true
+			}
+		}
+	}
+
+	// Segments still running, in the order they were started
+	var open []*segmentRun
+	for _, run := range runs {
+		open = append(open, run)
+	}
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].start.Before(open[j].start)
+	})
+	for _, run := range open {
+		report = append(report, run.finish(now, true))
+	}
+	return report
+}
@@ -0,0 +1,91 @@
+package clock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSegmentReport(t *testing.T) {
+	at := func(seconds int) time.Time {
+		return testStart.Add(time.Duration(seconds) * time.Second)
+	}
+	events := []Event{
+		{Type: EventStart, Counter: 1, Time: at(0), Segment: 1, Title: "Opening", Duration: 300},
+		{Type: EventStart, Counter: 2, Time: at(10)}, // Not a rundown segment
+		{Type: EventPause, Counter: 1, Time: at(60)},
+		{Type: EventPause, Counter: 1, Time: at(90)}, // Already paused
+		{Type: EventResume, Counter: 1, Time: at(120)},
+		{Type: EventExpire, Counter: 1, Time: at(360)},
+		{Type: EventStart, Counter: 1, Time: at(400), Segment: 2, Title: "Keynote", Duration: 600},
+		{Type: EventStart, Counter: 3, Time: at(410), Segment: 3, Title: "Stream", Duration: 60},
+		{Type: EventStop, Counter: 3, Time: at(450)},
+		{Type: EventPause, Counter: 1, Time: at(480)},
+	}
+
+	report := segmentReport(events, at(500))
+	want := []SegmentTiming{
+		{Segment: 1, Title: "Opening", Counter: 1, Start: at(0), End: at(400), Planned: 300, Actual: 340, Paused: 60, Overrun: 40, Expired: true},
+		{Segment: 3, Title: "Stream", Counter: 3, Start: at(410), End: at(450), Planned: 60, Actual: 40, Paused: 0, Overrun: -20},
+		{Segment: 2, Title: "Keynote", Counter: 1, Start: at(400), Running: true, Planned: 600, Actual: 80, Paused: 20, Overrun: -520},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("report = %+v, want %d segments", report, len(want))
+	}
+	for i := range want {
+		if report[i] != want[i] {
+			t.Errorf("segment %d = %+v, want %+v", i+1, report[i], want[i])
+		}
+	}
+}
+
+func TestTimingLogRundown(t *testing.T) {
+	engine, fake := newTestEngine(t, nil)
+	engine.LoadRundown([]Segment{
+		{Title: "Opening", Duration: time.Minute, Counter: 1},
+		{Title: "Keynote", Duration: 10 * time.Minute, Counter: 1},
+	})
+
+	engine.mutex.Lock()
+	engine.rundownGo()
+	engine.mutex.Unlock()
+	fake.Advance(30 * time.Second)
+	engine.PauseCounter(1)
+	fake.Advance(15 * time.Second)
+	engine.ResumeCounter(1)
+	fake.Advance(45 * time.Second)
+	engine.mutex.Lock()
+	engine.rundownNext()
+	engine.mutex.Unlock()
+
+	report := engine.SegmentReport()
+	if len(report) != 2 {
+		t.Fatalf("report = %+v, want 2 segments", report)
+	}
+	if r := report[0]; r.Title != "Opening" || r.Actual != 75 || r.Paused != 15 || r.Overrun != 15 || r.Running {
+		t.Errorf("first segment = %+v, want 75s actual, 15s paused and 15s overrun", r)
+	}
+	if r := report[1]; r.Title != "Keynote" || !r.Running || r.Actual != 0 {
+		t.Errorf("second segment = %+v, want a running Keynote", r)
+	}
+}
+
+func TestTimingLogPersisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	engine, fake := newTestEngine(t, func(o *EngineOptions) {
+		o.StateFile = stateFile
+	})
+	engine.StartCounter(1, true, 10*time.Minute)
+	fake.Advance(time.Minute)
+	engine.StopCounter(1)
+	engine.saveState()
+
+	restored, _ := newTestEngine(t, func(o *EngineOptions) {
+		o.StateFile = stateFile
+		o.TimeSource = fake
+	})
+	events := restored.TimingLog()
+	if len(events) != 2 || events[0].Type != EventStart || events[1].Type != EventStop {
+		t.Errorf("restored timing log = %+v, want start and stop", events)
+	}
+}
@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+/*
+ * Webhooks and external commands for counter events
+ */
+
+// webhookBackoff is the delay before the first retry, doubled on each retry
+var webhookBackoff = time.Second
+
+type webhook struct {
+	url     string
+	client  *http.Client
+	retries int
+}
+
+// initEventHooks starts the configured webhooks and event command
+func (engine *Engine) initEventHooks(options *EngineOptions) {
+	for _, url := range options.Webhooks {
+		w := &webhook{
+			url:     url,
+			client:  &http.Client{Timeout: time.Duration(options.WebhookTimeout) * time.Millisecond},
+			retries: options.WebhookRetries,
+		}
+		log.Printf("Sending counter events to webhook %s", url)
+		go w.run(engine.events.Subscribe())
+	}
+
+	if options.EventCommand != "" {
+		log.Printf("Running %s on counter events", options.EventCommand)
+		go runEventCommand(options.EventCommand, engine.events.Subscribe())
+	}
+}
+
+func (w *webhook) run(events chan Event) {
+	for e := range events {
+		if err := w.post(e); err != nil {
+			log.Printf("Webhook %s: %v", w.url, err)
+		}
+	}
+}
+
+// post sends the event as JSON, retrying on errors and non 2xx responses
+func (w *webhook) post(e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	backoff := webhookBackoff
+	for attempt := 0; ; attempt++ {
+		err = w.send(body)
+		if err == nil || attempt >= w.retries {
+			return err
+		}
+		log.Printf("Webhook %s: %v, retrying in %v", w.url, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *webhook) send(body []byte) error {
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return nil
+}
+
+// runEventCommand runs the command for each event with the event as JSON on stdin.
+// The event type and counter are also passed in the CLOCK_EVENT and CLOCK_COUNTER
+// environment variables.
+func runEventCommand(command string, events chan Event) {
+	for e := range events {
+		body, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("Event command: %v", err)
+			continue
+		}
+		cmd := exec.Command(command) // #nosec the command is from the clock configuration
+		cmd.Stdin = bytes.NewReader(body)
+		cmd.Env = append(os.Environ(),
+			"CLOCK_EVENT="+e.Type,
+			"CLOCK_COUNTER="+strconv.Itoa(e.Counter),
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			log.Printf("Event command %s: %v: %s", command, err, out)
+		}
+	}
+}
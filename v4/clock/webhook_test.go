@@ -0,0 +1,106 @@
+package clock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookPayload(t *testing.T) {
+	events := make(chan Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("content type = %q, want application/json", ct)
+		}
+		var e Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+		events <- e
+	}))
+	defer server.Close()
+
+	engine, _ := newTestEngine(t, func(o *EngineOptions) {
+		o.Webhooks = []string{server.URL}
+		o.WebhookTimeout = 1000
+	})
+	engine.StartCounter(2, true, 5*time.Minute)
+
+	select {
+	case e := <-events:
+		if e.Type != EventStart || e.Counter != 2 || !e.Countdown {
+			t.Errorf("got %s event on counter %d (countdown %v), want start on counter 2 (countdown true)", e.Type, e.Counter, e.Countdown)
+		}
+		if e.Seconds != 300 || e.Text != "00:05:00" {
+			t.Errorf("seconds = %d, text = %q, want 300 and 00:05:00", e.Seconds, e.Text)
+		}
+		if e.Clock != engine.uuid {
+			t.Errorf("clock = %q, want %q", e.Clock, engine.uuid)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook request received")
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	defer func(b time.Duration) { webhookBackoff = b }(webhookBackoff)
+	webhookBackoff = time.Millisecond
+
+	tests := []struct {
+		name     string
+		failures int32 // Requests answered with an error before succeeding
+		retries  int
+		attempts int32
+		ok       bool
+	}{
+		{"success", 0, 2, 1, true},
+		{"retried", 2, 2, 3, true},
+		{"retries exhausted", 5, 2, 3, false},
+		{"no retries", 1, 0, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+
+			w := &webhook{url: server.URL, client: &http.Client{Timeout: time.Second}, retries: tt.retries}
+			err := w.post(Event{Type: EventStop, Counter: 1})
+			if (err == nil) != tt.ok {
+				t.Errorf("post error = %v, want success %v", err, tt.ok)
+			}
+			if n := atomic.LoadInt32(&attempts); n != tt.attempts {
+				t.Errorf("attempts = %d, want %d", n, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	w := &webhook{url: server.URL, client: &http.Client{Timeout: 50 * time.Millisecond}}
+	start := time.Now()
+	if err := w.post(Event{Type: EventExpire}); err == nil {
+		t.Error("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("post took %v, expected the client timeout to stop it", elapsed)
+	}
+}
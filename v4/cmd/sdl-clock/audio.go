@@ -48,14 +48,19 @@ func checkBeep(s *clock.State, i int) {
 	clk := s.Clocks[i]
 	if clk.Mode == clock.Countdown {
 		if clk.Hours == 0 && clk.Minutes == 0 {
-			if clk.Seconds <= 5 && lastBeep[i] > clk.Seconds {
-				if clk.Seconds == 0 {
+			// Sub-second timers count down through the fractions, beep when they reach the whole second
+			left := clk.Seconds
+			if clk.SubSeconds && clk.Fraction > 0 {
+				left++
+			}
+			if left <= 5 && lastBeep[i] > left {
+				if left == 0 {
 					longBeep.Play(-1, 0)
 				} else {
 					shortBeep.Play(-1, 0)
 				}
 			}
-			lastBeep[i] = clk.Seconds
+			lastBeep[i] = left
 		}
 	}
 }
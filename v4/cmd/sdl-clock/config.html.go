@@ -35,6 +35,44 @@ const configHTML = `
 				</fieldset>
 			</form>
 
+			<form action="/undo" method="post">
+				<fieldset>
+					<legend>Undo timer operation</legend>
+					<p>Reverts the last start, stop, modify, pause, resume or signal color change on a timer, restoring its previous target, duration, paused state and color.
+					The same can be done with the /clock/timer/*/undo OSC command.</p>
+					<label for="undo-counter">
+						<span>Timer number</span>
+						<input type="number" min="0" id="undo-counter" name="counter" value="0" />
+					</label>
+					<input type="submit" value="undo" />
+				</fieldset>
+			</form>
+
+			<fieldset>
+				<legend>Timing report</legend>
+				<p>Counter starts, stops, pauses, resumes, modifications and expiries are logged with their times.
+				The segment report compares the planned and actual durations of each rundown segment, without the time spent paused.
+				The log can be cleared with the /clock/log/clear OSC command.</p>
+				<ul>
+					<li>Timing log: <a href="/timing/log.csv">CSV</a>, <a href="/timing/log.json">JSON</a></li>
+					<li>Segment report: <a href="/timing/segments.csv">CSV</a>, <a href="/timing/segments.json">JSON</a></li>
+				</ul>
+			</fieldset>
+
+			<form action="/rundown" method="post" enctype="multipart/form-data">
+				<fieldset>
+					<legend>Rundown</legend>
+					<p>Upload a rundown as a CSV file with a header row, or as a JSON array of objects with the same fields.
+					Columns are title, duration ([[HH:]MM:]SS), start (hard start time, HH:MM[:SS]), counter, color, background and auto.
+					A row without a duration counts down to the start time of the following row.
+					The rundown is loaded immediately and saved to the configured rundown file.</p>
+					<label for="rundown-upload"><span>Rundown file</span>
+						<input type="file" id="rundown-upload" name="rundown" />
+					</label>
+					<input type="submit" value="upload" />
+				</fieldset>
+			</form>
+
 			<form action="/save" method="post">
 				<fieldset>
 					<legend>General settings</legend>
@@ -102,6 +140,11 @@ const configHTML = `
 						<input type="number" min="0" id="Timeout" name="Timeout" value="{{.EngineOptions.Timeout}}" />
 					</label>
 
+					<label for="state-file">
+						<span>File for saving the timer and source state over restarts, leave empty to disable</span>
+						<input type="text" id="state-file" name="state-file" value="{{.EngineOptions.StateFile}}" />
+					</label>
+
 					<label for="ShowInfo">
 						<span>Time to show clock information on startup, seconds</span>
 						<input type="number" min="0" id="ShowInfo" name="ShowInfo" value="{{.EngineOptions.ShowInfo}}" />
@@ -122,6 +165,16 @@ const configHTML = `
 					Files should be named with the number (eg 1.png or 01.jpeg). Supported filetypes are BMP, PNG and JPEG.</p>
 
 
+					<label for="rundown">
+						<span>Rundown file to load on startup, in CSV or JSON format</span>
+						<input type="text" id="rundown" name="rundown" value="{{.Rundown}}" />
+					</label>
+
+					<label for="hard-out">
+						<span>Show hard out time as HH:MM:SS, compared against the remaining rundown. Leave empty to disable.</span>
+						<input type="text" id="hard-out" name="hard-out" value="{{.EngineOptions.HardOut}}" />
+					</label>
+
 					<label for="BackgroundColor">
 						<span>Background color, used if no background image is provided</span>
 						<input type="color" id="BackgroundColor" name="BackgroundColor" value="{{.BackgroundColor}}" />
@@ -154,199 +207,223 @@ const configHTML = `
 					</ol>
 				</p>
 
-				<fieldset>
-					<legend>Source 1</legend>
-
-					<label for="source1-text">
-						<span>Text label for time source</span>
-						<input type="text" id="source1-text" name="source1-text" value="{{.EngineOptions.Source1.Text}}" />
-					</label>
-
-					<label for="source1-ltc">
-						<span>Enable LTC input on this source</span>
-						<input type="checkbox" id="source1-ltc" name="source1-ltc" {{if .EngineOptions.Source1.LTC}} checked {{end}} />
-					</label>
-
-					<label for="source1-timer">
-						<span>Enable input from the associated timer</span>
-						<input type="checkbox" id="source1-timer" name="source1-timer" {{if .EngineOptions.Source1.Timer}} checked {{end}} />
-					</label>
-
-					<label for="source1-counter">
-						<span>Timer number to use (0-9)</span>
-						<input type="number" min="0" max="9" id="source1-counter" name="source1-counter" value="{{.EngineOptions.Source1.Counter}}" />
-					</label>
-
-					<label for="source1-tod">
-						<span>Enable time of day input on this source</span>
-						<input type="checkbox" id="source1-tod" name="source1-tod" {{if .EngineOptions.Source1.Tod}} checked {{end}} />
-					</label>
-
-					<label for="source1-timezone">
-						<span>Timezone for the time of day input</span>
-						{{$selected := .EngineOptions.Source1.TimeZone}}
-						<select id="source1-timezone" name="source1-timezone" >
-							{{ range $tz := .Timezones }}
-								<option {{if eq $selected $tz}} selected {{end}}>{{$tz}}</option>
-							{{ end }}
-						</select>
-					</label>
-
-					<label for="source1-hidden">
-						<span>Initially hide this source. Can be toggled by OSC on runtime.</span>
-						<input type="checkbox" id="source1-hidden" name="source1-hidden" {{if .EngineOptions.Source1.Hidden}} checked {{end}} />
-					</label>
+				<label for="counters">
+					<span>Number of timer counters</span>
+					<input type="number" min="1" id="counters" name="counters" value="{{.EngineOptions.Counters}}" />
+				</label>
 
-					<label for="source1-overtime-color">
-						<span>Background color for overtime countdowns</span>
-						<input type="color" id="source1-overtime-color" name="source1-overtime-color" value="{{.EngineOptions.Source1.OvertimeColor}}" />
-					</label>
+				<label for="sources">
+					<span>Number of time sources</span>
+					<input type="number" min="4" id="sources" name="sources" value="{{.Sources}}" />
+				</label>
+				<p>The clock faces use the first four sources, the rest are available for OSC feedback.
+				Added sources start with default settings and can be edited after saving.</p>
 
-				</fieldset>
+				{{range .SourceList}}
 				<fieldset>
-					<legend>Source 2</legend>
+					<legend>Source {{.Number}}</legend>
 
-					<label for="source2-text">
+					<label for="source{{.Number}}-text">
 						<span>Text label for time source</span>
-						<input type="text" id="source2-text" name="source2-text" value="{{.EngineOptions.Source2.Text}}" />
+						<input type="text" id="source{{.Number}}-text" name="source{{.Number}}-text" value="{{.Text}}" />
 					</label>
 
-					<label for="source2-ltc">
+					<label for="source{{.Number}}-ltc">
 						<span>Enable LTC input on this source</span>
-						<input type="checkbox" id="source2-ltc" name="source2-ltc" {{if .EngineOptions.Source2.LTC}} checked {{end}} />
+						<input type="checkbox" id="source{{.Number}}-ltc" name="source{{.Number}}-ltc" {{if .LTC}} checked {{end}} />
 					</label>
 
-					<label for="source2-timer">
+					<label for="source{{.Number}}-timer">
 						<span>Enable input from the associated timer</span>
-						<input type="checkbox" id="source2-timer" name="source2-timer" {{if .EngineOptions.Source2.Timer}} checked {{end}} />
+						<input type="checkbox" id="source{{.Number}}-timer" name="source{{.Number}}-timer" {{if .Timer}} checked {{end}} />
 					</label>
 
-					<label for="source2-counter">
-						<span>Timer number to use (0-9)</span>
-						<input type="number" min="0" max="9" id="source2-counter" name="source2-counter" value="{{.EngineOptions.Source2.Counter}}" />
+					<label for="source{{.Number}}-counter">
+						<span>Timer number to use (0-{{$.LastCounter}})</span>
+						<input type="number" min="0" max="{{$.LastCounter}}" id="source{{.Number}}-counter" name="source{{.Number}}-counter" value="{{.Counter}}" />
 					</label>
 
-					<label for="source2-tod">
+					<label for="source{{.Number}}-tod">
 						<span>Enable time of day input on this source</span>
-						<input type="checkbox" id="source2-tod" name="source2-tod" {{if .EngineOptions.Source2.Tod}} checked {{end}} />
+						<input type="checkbox" id="source{{.Number}}-tod" name="source{{.Number}}-tod" {{if .Tod}} checked {{end}} />
 					</label>
 
-					<label for="source2-timezone">
+					<label for="source{{.Number}}-timezone">
 						<span>Timezone for the time of day input</span>
-						{{$selected = .EngineOptions.Source2.TimeZone}}
-						<select id="source2-timezone" name="source2-timezone" >
-							{{ range $tz := .Timezones }}
+						{{$selected := .TimeZone}}
+						<select id="source{{.Number}}-timezone" name="source{{.Number}}-timezone" >
+							{{ range $tz := $.Timezones }}
 								<option {{if eq $selected $tz}} selected {{end}}>{{$tz}}</option>
 							{{ end }}
 						</select>
 					</label>
 
-					<label for="source2-hidden">
-						<span>Initially hide this source. Can be toggled by OSC on runtime.</span>
-						<input type="checkbox" id="source2-hidden" name="source2-hidden" {{if .EngineOptions.Source2.Hidden}} checked {{end}} />
+					<label for="source{{.Number}}-inputs">
+						<span>Ordered list of inputs, overrides the checkboxes above. The first active input is displayed:
+						<code>ltc</code>, <code>timer</code>, <code>counter:N</code>, <code>tod[:zone]</code>, <code>date[:zone]</code>,
+						<code>utc</code>, <code>show</code>, <code>label:text</code>, eg. <code>counter:3, counter:4, tod:Asia/Tokyo</code></span>
+						<input type="text" id="source{{.Number}}-inputs" name="source{{.Number}}-inputs" value="{{.Inputs}}" />
 					</label>
 
-					<label for="source2-overtime-color">
-						<span>Background color for overtime countdowns</span>
-						<input type="color" id="source2-overtime-color" name="source2-overtime-color" value="{{.EngineOptions.Source2.OvertimeColor}}" />
+					<label for="source{{.Number}}-hidden">
+						<span>Initially hide this source. Can be toggled by OSC on runtime.</span>
+						<input type="checkbox" id="source{{.Number}}-hidden" name="source{{.Number}}-hidden" {{if .Hidden}} checked {{end}} />
 					</label>
-				</fieldset>
-				<fieldset>
-					<legend>Source 3</legend>
 
-					<label for="source3-text">
-						<span>Text label for time source</span>
-						<input type="text" id="source3-text" name="source3-text" value="{{.EngineOptions.Source3.Text}}" />
+					<label for="source{{.Number}}-overtime-color">
+						<span>Background color for overtime countdowns</span>
+						<input type="color" id="source{{.Number}}-overtime-color" name="source{{.Number}}-overtime-color" value="{{.OvertimeColor}}" />
 					</label>
 
-					<label for="source3-ltc">
-						<span>Enable LTC input on this source</span>
-						<input type="checkbox" id="source3-ltc" name="source3-ltc" {{if .EngineOptions.Source3.LTC}} checked {{end}} />
+					<label for="source{{.Number}}-overtime-count-mode">
+						<span>Countdown readout for overtime timers</span>
+						<select name="source{{.Number}}-overtime-count-mode" id="source{{.Number}}-overtime-count-mode">
+							<option value="" {{if eq .OvertimeCountMode ""}} selected {{end}}>Use the global setting</option>
+							<option value="zero" {{if eq .OvertimeCountMode "zero"}} selected {{end}}>Show 00:00:00</option>
+							<option value="blank" {{if eq .OvertimeCountMode "blank"}} selected {{end}}>Blank display</option>
+							<option value="continue" {{if eq .OvertimeCountMode "continue"}} selected {{end}}>Continue counting up</option>
+						</select>
 					</label>
 
-					<label for="source3-timer">
-						<span>Enable input from the associated timer on this source</span>
-						<input type="checkbox" id="source3-timer" name="source3-timer" {{if .EngineOptions.Source3.Timer}} checked {{end}} />
+					<label for="source{{.Number}}-overtime-visibility">
+						<span>Extra visibility for overtime timers</span>
+						<select name="source{{.Number}}-overtime-visibility" id="source{{.Number}}-overtime-visibility">
+							<option value="" {{if eq .OvertimeVisibility ""}} selected {{end}}>Use the global setting</option>
+							<option value="blink" {{if eq .OvertimeVisibility "blink"}} selected {{end}}>Blink readout</option>
+							<option value="background" {{if eq .OvertimeVisibility "background"}} selected {{end}}>Change background color</option>
+							<option value="both" {{if eq .OvertimeVisibility "both"}} selected {{end}}>Change background + blink</option>
+							<option value="none" {{if eq .OvertimeVisibility "none"}} selected {{end}}>No extra visibility</option>
+						</select>
 					</label>
 
-					<label for="source3-counter">
-						<span>Timer number to use (0-9)</span>
-						<input type="number" min="0" max="9" id="source3-counter" name="source3-counter" value="{{.EngineOptions.Source3.Counter}}" />
+					<label for="source{{.Number}}-time-format">
+						<span>Time of day format</span>
+						<select name="source{{.Number}}-time-format" id="source{{.Number}}-time-format">
+							<option value="" {{if eq .TimeFormat ""}} selected {{end}}>Use the global setting</option>
+							<option value="12h" {{if eq .TimeFormat "12h"}} selected {{end}}>12 hour</option>
+							<option value="24h" {{if eq .TimeFormat "24h"}} selected {{end}}>24 hour</option>
+						</select>
 					</label>
 
-					<label for="source3-tod">
-						<span>Enable time of day input on this source</span>
-						<input type="checkbox" id="source3-tod" name="source3-tod" {{if .EngineOptions.Source3.Tod}} checked {{end}} />
+					<label for="source{{.Number}}-hide-seconds">
+						<span>Hide seconds from the time of day</span>
+						<input type="checkbox" id="source{{.Number}}-hide-seconds" name="source{{.Number}}-hide-seconds" {{if .HideSeconds}} checked {{end}} />
 					</label>
 
-					<label for="source3-timezone">
-						<span>Timezone for the time of day input</span>
-						{{$selected = .EngineOptions.Source3.TimeZone}}
-						<select id="source3-timezone" name="source3-timezone" >
-							{{ range $tz := .Timezones }}
-								<option {{if eq $selected $tz}} selected {{end}}>{{$tz}}</option>
-							{{ end }}
-						</select>
+					<label for="source{{.Number}}-sub-seconds">
+						<span>Show tenths of a second on timers under this many seconds. Set to 0 to disable.</span>
+						<input type="number" min="0" id="source{{.Number}}-sub-seconds" name="source{{.Number}}-sub-seconds" value="{{.SubSeconds}}" />
 					</label>
 
-					<label for="source3-hidden">
-						<span>Initially hide this source. Can be toggled by OSC on runtime.</span>
-						<input type="checkbox" id="source3-hidden" name="source3-hidden" {{if .EngineOptions.Source3.Hidden}} checked {{end}} />
+					<label for="source{{.Number}}-hundredths">
+						<span>Show hundredths instead of tenths of a second</span>
+						<input type="checkbox" id="source{{.Number}}-hundredths" name="source{{.Number}}-hundredths" {{if .Hundredths}} checked {{end}} />
 					</label>
 
-					<label for="source3-overtime-color">
-						<span>Background color for overtime countdowns</span>
-						<input type="color" id="source3-overtime-color" name="source3-overtime-color" value="{{.EngineOptions.Source3.OvertimeColor}}" />
+					<label for="source{{.Number}}-back-time">
+						<span>Show the time of day a running countdown will end</span>
+						<input type="checkbox" id="source{{.Number}}-back-time" name="source{{.Number}}-back-time" {{if .BackTime}} checked {{end}} />
 					</label>
-				</fieldset>
-				<fieldset>
-					<legend>Source 4</legend>
 
-					<label for="source4-text">
-						<span>Text label for time source</span>
-						<input type="text" id="source4-text" name="source4-text" value="{{.EngineOptions.Source4.Text}}" />
+					<label for="source{{.Number}}-format">
+						<span>Format for the clock text, for example <code>%H:%M</code> or <code>%-Mm %Ss</code>. Leave empty for the default.</span>
+						<input type="text" id="source{{.Number}}-format" name="source{{.Number}}-format" value="{{.Format}}" />
 					</label>
 
-					<label for="source4-ltc">
-						<span>Enable LTC input on this source</span>
-						<input type="checkbox" id="source4-ltc" name="source4-ltc" {{if .EngineOptions.Source4.LTC}} checked {{end}} />
+					<label for="source{{.Number}}-overtime-format">
+						<span>Format for expired countdowns, for example <code>-%M:%S</code>. Leave empty to use the format above.</span>
+						<input type="text" id="source{{.Number}}-overtime-format" name="source{{.Number}}-overtime-format" value="{{.OvertimeFormat}}" />
 					</label>
+				</fieldset>
+				{{end}}
+			</fieldset>
 
-					<label for="source4-timer">
-						<span>Enable input from the associated timer on this source</span>
-						<input type="checkbox" id="source4-timer" name="source4-timer" {{if .EngineOptions.Source4.Timer}} checked {{end}} />
-					</label>
+			<fieldset>
+				<legend>Timer groups</legend>
+				<p>Named groups of timers, one group per line in the format <code>name timers</code>, for example <code>stage 1,3</code>.
+				Timer commands sent to <code>/clock/group/name/...</code> are run on all timers of the group.</p>
+				<label for="groups">
+					<span>Groups</span>
+					<textarea id="groups" name="groups" rows="4" cols="50">{{range .EngineOptions.Groups}}{{.}}
+{{end}}</textarea>
+				</label>
+			</fieldset>
 
-					<label for="source4-counter">
-						<span>Timer number to use (0-9)</span>
-						<input type="number" min="0" max="9" id="source4-counter" name="source4-counter" value="{{.EngineOptions.Source4.Counter}}" />
-					</label>
+			<fieldset>
+				<legend>Linked timers</legend>
+				<p>A linked timer follows another timer with an offset and scale, one link per line in the format
+				<code>timer source [offset] [scale]</code>, for example <code>2 1 -5:00</code> for timer 2 showing timer 1 minus five minutes.
+				Starting, pausing, modifying and stopping the source timer carries over to the linked timer, which keeps its own thresholds and signal colors.</p>
+				<label for="timer-links">
+					<span>Links</span>
+					<textarea id="timer-links" name="timer-links" rows="4" cols="50">{{range .EngineOptions.Links}}{{.}}
+{{end}}</textarea>
+				</label>
+			</fieldset>
 
-					<label for="source4-tod">
-						<span>Enable time of day input on this source</span>
-						<input type="checkbox" id="source4-tod" name="source4-tod" {{if .EngineOptions.Source4.Tod}} checked {{end}} />
-					</label>
+			<fieldset>
+				<legend>Timer presets</legend>
+				<p>Presets are loaded on a timer with <code>/clock/timer/*/preset</code> and the preset name or number, one preset per line
+				in the format <code>name;duration;direction;title;color;background;thresholds</code>. The duration is <code>[[HH:]MM:]SS</code>
+				and the direction <code>down</code> or <code>up</code>, count ups start from zero and have no duration. Title, colors and thresholds are optional, for example
+				<code>keynote;20:00;down;Keynote;#FFFFFF;#000080;300 #FFFF00, 60 #FF0000</code></p>
+				<label for="presets">
+					<span>Presets</span>
+					<textarea id="presets" name="presets" rows="6" cols="50">{{range .EngineOptions.Presets}}{{.}}
+{{end}}</textarea>
+				</label>
+			</fieldset>
 
-					<label for="source4-timezone">
-						<span>Time zone for the time of day input</span>
-						{{$selected = .EngineOptions.Source4.TimeZone}}
-						<select id="source4-timezone" name="source4-timezone" >
-							{{ range $tz := .Timezones }}
-								<option {{if eq $selected $tz}} selected {{end}}>{{$tz}}</option>
-							{{ end }}
-						</select>
-					</label>
+			<fieldset>
+				<legend>Schedule</legend>
+				<p>Commands to run at a time of day, one per line in the format <code>HH:MM[:SS] days source command</code>.
+				Days is daily, weekdays, weekends or a list of days and ranges like mon,wed,fri-sun. The time of day is in the time zone
+				of the given source. The command is an OSC address followed by its arguments, quote arguments containing spaces.
+				For example <code>19:00 weekdays 1 /clock/timer/2/countdown/target 19:30:00</code></p>
+				<label for="schedule">
+					<span>Scheduled commands</span>
+					<textarea id="schedule" name="schedule" rows="6" cols="50">{{range .EngineOptions.Schedule}}{{.}}
+{{end}}</textarea>
+				</label>
+			</fieldset>
 
-					<label for="source4-hidden">
-						<span>Initially hide this source. Can be toggled by OSC on runtime.</span>
-						<input type="checkbox" id="source4-hidden" name="source4-hidden" {{if .EngineOptions.Source4.Hidden}} checked {{end}} />
-					</label>
+			<fieldset>
+				<legend>Timer actions</legend>
+				<p>Commands to run once when a countdown expires or reaches a threshold, one per line. The command is an
+				OSC address followed by its arguments, as with the schedule.</p>
+				<label for="timer-expire-actions">
+					<span>Expire actions, <code>counter command</code>, eg. <code>1 /clock/timer/0/countup</code></span>
+					<textarea id="timer-expire-actions" name="timer-expire-actions" rows="4" cols="50">{{range .EngineOptions.ExpireActions}}{{.}}
+{{end}}</textarea>
+				</label>
+				<label for="timer-threshold-actions">
+					<span>Threshold actions, <code>counter seconds command</code>, eg. <code>1 60 /clock/flash</code></span>
+					<textarea id="timer-threshold-actions" name="timer-threshold-actions" rows="4" cols="50">{{range .EngineOptions.ThresholdActions}}{{.}}
+{{end}}</textarea>
+				</label>
+			</fieldset>
 
-					<label for="source4-overtime-color">
-						<span>Background color for overtime countdowns</span>
-						<input type="color" id="source4-overtime-color" name="source4-overtime-color" value="{{.EngineOptions.Source4.OvertimeColor}}" />
-					</label>
-				</fieldset>
+			<fieldset>
+				<legend>Counter events</legend>
+				<p>Counter start, stop, pause, resume, warning, end and expire events are sent as JSON to the webhooks
+				and to the event command. The warning and end events use the signal thresholds.</p>
+				<label for="webhooks">
+					<span>Webhook URLs for HTTP POST requests, one per line</span>
+					<textarea id="webhooks" name="webhooks" rows="3" cols="50">{{range .EngineOptions.Webhooks}}{{.}}
+{{end}}</textarea>
+				</label>
+				<label for="webhook-timeout">
+					<span>Webhook request timeout, milliseconds</span>
+					<input type="number" min="1" id="webhook-timeout" name="webhook-timeout" value="{{.EngineOptions.WebhookTimeout}}" />
+				</label>
+				<label for="webhook-retries">
+					<span>Retries for failed webhook requests</span>
+					<input type="number" min="0" id="webhook-retries" name="webhook-retries" value="{{.EngineOptions.WebhookRetries}}" />
+				</label>
+				<label for="event-command">
+					<span>Command to run on events, receives the event as JSON on stdin</span>
+					<input type="text" id="event-command" name="event-command" value="{{.EngineOptions.EventCommand}}" />
+				</label>
 			</fieldset>
 
 			<fieldset>
@@ -409,6 +486,13 @@ const configHTML = `
 					<input type="color" id="signal-color-end" name="signal-color-end" value="{{.EngineOptions.SignalColorEnd}}" />
 				</label>
 
+				<label for="timer-thresholds">
+					<span>Per counter threshold profiles, one counter per line. Each step is seconds left or percentage of the
+					countdown, a color and optional blink on/off milliseconds, eg. <code>1 300 #FFFF00, 10% #FF8000, 60 #FF0000 250/250</code></span>
+					<textarea id="timer-thresholds" name="timer-thresholds" rows="4" cols="50">{{range .EngineOptions.Thresholds}}{{.}}
+{{end}}</textarea>
+				</label>
+
 				<label for="signal-hw-type">
 						<span>Signal hardware type</span>
 						<select name="signal-hw-type" id="signal-hw-type">
@@ -442,12 +526,12 @@ const configHTML = `
 
 				<label for="mitti">
 					<span>Timer number for OSC feedback from Mitti</span>
-					<input type="number" min="0" max="9" id="mitti" name="mitti" value="{{.EngineOptions.Mitti}}" />
+					<input type="number" min="0" max="{{.LastCounter}}" id="mitti" name="mitti" value="{{.EngineOptions.Mitti}}" />
 				</label>
 
 				<label for="millumin">
 					<span>Timer number for OSC feedback from Millumin</span>
-					<input type="number" min="0" max="9" id="millumin" name="millumin" value="{{.EngineOptions.Millumin}}" />
+					<input type="number" min="0" max="{{.LastCounter}}" id="millumin" name="millumin" value="{{.EngineOptions.Millumin}}" />
 				</label>
 
 
@@ -471,12 +555,12 @@ const configHTML = `
 
 				<label for="upd-timer-1">
 					<span>Timer number for StageTimer2 UDP timer 1 from port 36700</span>
-					<input type="number" min="0" max="9" id="udp-timer-1" name="udp-timer-1" value="{{.EngineOptions.UDPTimer1}}" />
+					<input type="number" min="0" max="{{.LastCounter}}" id="udp-timer-1" name="udp-timer-1" value="{{.EngineOptions.UDPTimer1}}" />
 				</label>
 
 				<label for="upd-timer-2">
 					<span>Timer number for StageTimer2 UDP timer 2 from port 36701</span>
-					<input type="number" min="0" max="9" id="udp-timer-2" name="udp-timer-2" value="{{.EngineOptions.UDPTimer2}}" />
+					<input type="number" min="0" max="{{.LastCounter}}" id="udp-timer-2" name="udp-timer-2" value="{{.EngineOptions.UDPTimer2}}" />
 				</label>
 			</fieldset>
 			<fieldset>
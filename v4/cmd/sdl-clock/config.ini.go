@@ -71,50 +71,102 @@ udp-timer-2={{.EngineOptions.UDPTimer2}}
 # 3. Time of day
 # 4. Blank display
 
-# Text label for time source
-source1.text={{.EngineOptions.Source1.Text}}
-# Set to true to enable LTC input on this source
-source1.ltc={{.EngineOptions.Source1.LTC}}
-# Set to true for countdown / count up timer input on this source
-source1.timer={{.EngineOptions.Source1.Timer}}
-# Counter number for timer support (0-9)
-source1.counter={{.EngineOptions.Source1.Counter}}
-# Set to true to enable time of day input on this source
-source1.tod={{.EngineOptions.Source1.Tod}}
-# Time zone for the time of day input
-source1.timezone={{.EngineOptions.Source1.TimeZone}}
-# Initially hide this source, can be toggled via OSC
-source1.hidden={{.EngineOptions.Source1.Hidden}}
-# Background color for overtime countdown timers
-source1.overtime-color={{.EngineOptions.Source1.OvertimeColor}}
-
-source2.text={{.EngineOptions.Source2.Text}}
-source2.ltc={{.EngineOptions.Source2.LTC}}
-source2.timer={{.EngineOptions.Source2.Timer}}
-source2.counter={{.EngineOptions.Source2.Counter}}
-source2.tod={{.EngineOptions.Source2.Tod}}
-source2.timezone={{.EngineOptions.Source2.TimeZone}}
-source2.hidden={{.EngineOptions.Source2.Hidden}}
-source2.overtime-color={{.EngineOptions.Source2.OvertimeColor}}
-
-source3.text={{.EngineOptions.Source3.Text}}
-source3.ltc={{.EngineOptions.Source3.LTC}}
-source3.timer={{.EngineOptions.Source3.Timer}}
-source3.counter={{.EngineOptions.Source3.Counter}}
-source3.tod={{.EngineOptions.Source3.Tod}}
-source3.timezone={{.EngineOptions.Source3.TimeZone}}
-source3.hidden={{.EngineOptions.Source3.Hidden}}
-source3.overtime-color={{.EngineOptions.Source3.OvertimeColor}}
-
-source4.text={{.EngineOptions.Source4.Text}}
-source4.ltc={{.EngineOptions.Source4.LTC}}
-source4.timer={{.EngineOptions.Source4.Timer}}
-source4.counter={{.EngineOptions.Source4.Counter}}
-source4.tod={{.EngineOptions.Source4.Tod}}
-source4.timezone={{.EngineOptions.Source4.TimeZone}}
-source4.hidden={{.EngineOptions.Source4.Hidden}}
-source4.overtime-color={{.EngineOptions.Source4.OvertimeColor}}
-
+# Number of timer counters, numbered from 0
+counters={{.EngineOptions.Counters}}
+
+# Number of clock display sources, numbered from 1. The clock faces use the first four sources,
+# the rest are available for OSC feedback
+sources={{.Sources}}
+
+# Options for each source:
+# sourceN.text - Text label for time source
+# sourceN.ltc - Set to true to enable LTC input on this source
+# sourceN.timer - Set to true for countdown / count up timer input on this source
+# sourceN.counter - Counter number for timer support
+# sourceN.tod - Set to true to enable time of day input on this source
+# sourceN.timezone - Time zone for the time of day input
+# sourceN.inputs - Ordered list of inputs, the first active one is displayed. Overrides ltc, timer and tod.
+#   ltc, timer, counter:N, tod, tod:ZONE, date, date:ZONE, utc, label:TEXT, eg. counter:3, counter:4, tod:Asia/Tokyo
+# sourceN.hidden - Initially hide this source, can be toggled via OSC
+# sourceN.overtime-color - Background color for overtime countdown timers
+# sourceN.overtime-count-mode - Overtime count mode for this source, empty to use the global setting
+# sourceN.overtime-visibility - Overtime visibility for this source, empty to use the global setting
+# sourceN.time-format - Time of day format for this source: 12h or 24h, empty to use the global setting
+# sourceN.hide-seconds - Set to true to hide the seconds from the time of day on this source
+{{range .SourceList}}
+source{{.Number}}.text={{.Text}}
+source{{.Number}}.ltc={{.LTC}}
+source{{.Number}}.timer={{.Timer}}
+source{{.Number}}.counter={{.Counter}}
+source{{.Number}}.tod={{.Tod}}
+source{{.Number}}.timezone={{.TimeZone}}
+source{{.Number}}.inputs={{.Inputs}}
+source{{.Number}}.hidden={{.Hidden}}
+source{{.Number}}.overtime-color={{.OvertimeColor}}
+source{{.Number}}.overtime-count-mode={{.OvertimeCountMode}}
+source{{.Number}}.overtime-visibility={{.OvertimeVisibility}}
+source{{.Number}}.time-format={{.TimeFormat}}
+source{{.Number}}.hide-seconds={{.HideSeconds}}
+source{{.Number}}.sub-seconds={{.SubSeconds}}
+source{{.Number}}.hundredths={{.Hundredths}}
+source{{.Number}}.back-time={{.BackTime}}
+source{{.Number}}.format={{.Format}}
+source{{.Number}}.overtime-format={{.OvertimeFormat}}
+{{end}}
+
+# Rundown file to load on startup, in CSV or JSON format. Leave empty to disable.
+# A rundown uploaded from the web configuration is saved to this file.
+rundown={{.Rundown}}
+
+# Time the show must end by, as HH:MM:SS or an ISO 8601 date-time. The remaining
+# rundown time is compared against it and shown on sources with the show input.
+hard-out={{.EngineOptions.HardOut}}
+
+# Named timer groups, controlled together with /clock/group/name/... commands.
+# group=name counters, eg. stage 1,3
+# The option can be repeated for multiple groups.
+{{range .EngineOptions.Groups}}group={{.}}
+{{end}}
+# Linked timers following another timer with an offset and scale.
+# timer-link=counter source [offset] [scale], where the offset is [-][[HH:]MM:]SS
+# and the scale a multiplier, eg. 2 1 -5:00 for counter 1 minus five minutes.
+# The option can be repeated for multiple counters.
+{{range .EngineOptions.Links}}timer-link={{.}}
+{{end}}
+# Timer presets, loaded on a timer with /clock/timer/*/preset name or number.
+# preset=name;duration;direction;title;color;background;thresholds
+# The duration is [[HH:]MM:]SS and the direction down or up, count ups start
+# from zero and have no duration. Title, colors and thresholds are optional,
+# eg. keynote;20:00;down;Keynote;#FFFFFF;#000080;300 #FFFF00, 60 #FF0000
+# The option can be repeated for multiple presets.
+{{range .EngineOptions.Presets}}preset={{.}}
+{{end}}
+# Scheduled commands, run at a time of day in the time zone of the given source.
+# Format: HH:MM[:SS] days source command
+# days is daily, weekdays, weekends or a list of days and ranges like mon,wed,fri-sun
+# command is an OSC address with its arguments, eg.
+# schedule=19:00 weekdays 1 /clock/timer/2/countdown/target 19:30:00
+# The option can be repeated for multiple entries.
+{{range .EngineOptions.Schedule}}schedule={{.}}
+{{end}}
+# Timer actions, run once when a countdown on the counter expires or reaches a threshold.
+# The command is an OSC address with its arguments, as with the schedule.
+# timer-expire-action=counter command, eg. 1 /clock/timer/0/countup
+# timer-threshold-action=counter seconds command, eg. 1 60 /clock/flash
+# The options can be repeated for multiple actions.
+{{range .EngineOptions.ExpireActions}}timer-expire-action={{.}}
+{{end}}{{range .EngineOptions.ThresholdActions}}timer-threshold-action={{.}}
+{{end}}
+# Counter events: start, stop, pause, resume, warning, end and expire.
+# The warning and end events use the signal thresholds.
+# Webhook URLs receive the events as JSON in HTTP POST requests, the option can be repeated.
+{{range .EngineOptions.Webhooks}}webhook={{.}}
+{{end}}# Timeout for webhook requests, in milliseconds
+webhook-timeout={{.EngineOptions.WebhookTimeout}}
+# Number of retries for failed webhook requests
+webhook-retries={{.EngineOptions.WebhookRetries}}
+# Command to run on each event, receives the event as JSON on stdin. Leave empty to disable.
+event-command={{.EngineOptions.EventCommand}}
 
 # Overtime behaviour
 
@@ -154,6 +206,14 @@ signal-threshold-end={{.EngineOptions.SignalThresholdEnd}}
 # End signal color (in HTML color format #FFFFFF)
 signal-color-end={{.EngineOptions.SignalColorEnd}}
 
+# Per counter signal threshold profiles, used instead of the thresholds above.
+# timer-thresholds=counter steps, where the steps are separated by commas.
+# Each step is seconds left or percentage of the countdown, a color and an
+# optional blink pattern as on/off milliseconds,
+# eg. 1 300 #FFFF00, 10% #FF8000, 60 #FF0000 250/250
+# The option can be repeated for multiple counters.
+{{range .EngineOptions.Thresholds}}timer-thresholds={{.}}
+{{end}}
 # Signal hardware type: supported: none, unicorn-hd
 signal-hw-type={{.SignalType}}
 
@@ -242,6 +302,9 @@ Debug={{.Debug}}
 # Flashing interval for ellapsed countdowns, in milliseconds
 Flash={{.EngineOptions.Flash}}
 
+# File for saving the timer and source state, so that running timers survive a restart. Leave empty to disable.
+state-file={{.EngineOptions.StateFile}}
+
 # Set to true to disable remote osc commands
 DisableOSC={{.EngineOptions.DisableOSC}}
 
@@ -11,3 +11,15 @@ const confirmHTML = `
 	</body>
 </html>
 `
+
+const rundownHTML = `
+<html>
+	<head>
+		<title>Clock-8001 configuration</title>
+	</head>
+	<body>
+		<h1>Rundown loaded</h1>
+		<p>Loaded a rundown with {{.}} segments. You can <a href="/">return to configuration editor</a>.</p>
+	</body>
+</html>
+`
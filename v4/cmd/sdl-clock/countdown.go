@@ -2,13 +2,11 @@ package main
 
 import (
 	"fmt"
+	"github.com/stanchan/clock-8001/v4/clock"
+	"github.com/stanchan/clock-8001/v4/debug"
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/ttf"
-	// "github.com/stanchan/clock-8001/v4/clock"
-	"github.com/stanchan/clock-8001/v4/debug"
 	"log"
-	"math"
-	"time"
 )
 
 var countdown struct {
@@ -16,8 +14,6 @@ var countdown struct {
 	smallFont *ttf.Font
 	bgColor   sdl.Color
 	color     sdl.Color
-	target    time.Time
-	loc       *time.Location
 }
 
 func initCountdown() {
@@ -36,41 +32,34 @@ func initCountdown() {
 	}
 	countdown.largeFont = f
 
-	countdown.loc, err = time.LoadLocation(options.EngineOptions.Source1.TimeZone)
-	check(err)
-
-	countdown.target, err = time.ParseInLocation("2006-01-02 15:04:05", options.CountdownTarget, countdown.loc)
-	check(err)
-	log.Printf("Target: %v", countdown.target)
-
 	countdown.color = sdl.Color{R: 255, G: 255, B: 255, A: 255}
 	countdown.bgColor = sdl.Color{R: 0, G: 0, B: 0, A: 255}
 
 	log.Printf("Countdown face intialized.")
 }
 
-func drawCountdown() {
-	debug.Printf("drawCountdown")
-
-	t := countdown.target.Sub(time.Now().In(countdown.loc))
+// startCountdown targets the source 1 counter to the configured countdown target
+func startCountdown(engine *clock.Engine) {
+	log.Printf("Countdown target: %s", options.CountdownTarget)
+	engine.TargetCounter(options.EngineOptions.Sources[0].Counter, options.CountdownTarget, true)
+}
 
-	hours := t.Truncate(time.Hour).Hours()
-	minutes := t.Truncate(time.Minute).Minutes() - (hours * 60)
-	seconds := t.Truncate(time.Second).Seconds() - (hours * 60 * 60) - (minutes * 60)
-	days := math.Floor(hours / 24)
-	hours -= days * 24
+// drawCountdown shows the days and the remaining time of the source 1 countdown
+func drawCountdown(state *clock.State) {
+	debug.Printf("drawCountdown")
 
-	if t.Seconds() < 0 {
-		days = 0.0
-		hours = 0.0
-		minutes = 0.0
-		seconds = 0.0
+	var days, hours, minutes, seconds int
+	if clk := state.Clocks[0]; clk.Mode == clock.Countdown && !clk.Expired {
+		days = clk.Days
+		hours = clk.Hours - clk.Days*24
+		minutes = clk.Minutes
+		seconds = clk.Seconds
 	}
 
-	dayTex := renderText(fmt.Sprintf("%.0f", days), countdown.largeFont, countdown.color)
+	dayTex := renderText(fmt.Sprintf("%d", days), countdown.largeFont, countdown.color)
 	defer dayTex.Destroy()
 
-	lineTex := renderText(fmt.Sprintf("%02.0f:%02.0f:%02.0f", hours, minutes, seconds), countdown.smallFont, countdown.color)
+	lineTex := renderText(fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds), countdown.smallFont, countdown.color)
 	defer lineTex.Destroy()
 
 	prepareCanvas()
@@ -65,6 +65,7 @@ type clockOptions struct {
 	Background      string               `long:"background" description:"Background image file location."`
 	BackgroundPath  string               `long:"background-path" description:"path to load OSC backgrounds from" default:"/boot"`
 	BackgroundColor string               `long:"background-color" description:"Background color, used if no background image is supplied" default:"#000000"`
+	Sources         int                  `long:"sources" description:"Number of clock display sources" default:"4"`
 	EngineOptions   *clock.EngineOptions
 
 	// Round clock stuff
@@ -101,7 +102,8 @@ type clockOptions struct {
 	AudioEnabled bool `long:"audio" description:"Play beeps when a timer is about to expire"`
 	TODBeep      bool `long:"tod-beep" description:"Play beeps on each hour on TOD clocks"`
 
-	CountdownTarget string `long:"countdown-target" default:"2020-12-24 00:00:00"`
+	CountdownTarget string `long:"countdown-target" description:"Target for the countdown face, as HH:MM:SS or a ISO 8601 date-time with an optional time zone name" default:"2020-12-24 00:00:00"`
+	Rundown         string `long:"rundown" description:"CSV or JSON rundown file to load on startup"`
 	Raspberry       bool   // Is the host a raspberry pi
 	ConfigTxt       string // /boot/config.txt contents
 
@@ -118,6 +120,26 @@ type clockOptions struct {
 
 var options clockOptions
 
+// sourceConfig pairs a display source with its number for the config templates
+type sourceConfig struct {
+	Number int
+	*clock.SourceOptions
+}
+
+// SourceList returns the numbered display sources for the config templates
+func (options clockOptions) SourceList() []sourceConfig {
+	list := make([]sourceConfig, len(options.EngineOptions.Sources))
+	for i, s := range options.EngineOptions.Sources {
+		list[i] = sourceConfig{Number: i + 1, SourceOptions: s}
+	}
+	return list
+}
+
+// LastCounter returns the highest valid counter number for the config templates
+func (options clockOptions) LastCounter() int {
+	return options.EngineOptions.Counters - 1
+}
+
 // Pixel coordinates for the 192x192 pixel clock circles
 var circlePixels = [][2]int32{
 	{0, 1},
@@ -3,6 +3,8 @@ package main
 import (
 	// "fmt"
 	"crypto/subtle"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"github.com/stanchan/clock-8001/v4/clock"
 	htmlTemplate "html/template"
@@ -10,31 +12,59 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"text/template"
 	"time"
 )
 
+// The running clock engine for the web interface
+var httpEngine struct {
+	sync.Mutex
+	engine *clock.Engine
+}
+
+// setRunningEngine makes the clock engine available to the web interface
+func setRunningEngine(engine *clock.Engine) {
+	httpEngine.Lock()
+	defer httpEngine.Unlock()
+	httpEngine.engine = engine
+}
+
+// runningEngine returns the clock engine, or nil if it hasn't been started yet
+func runningEngine() *clock.Engine {
+	httpEngine.Lock()
+	defer httpEngine.Unlock()
+	return httpEngine.engine
+}
+
 func runHTTP() {
+	// The timing reports don't depend on the configuration file
+	http.HandleFunc("/timing/", basicAuth(timingHandler))
+
 	if options.configFile == "" {
 		// No config file specified, can't save the config
 		log.Printf("No config specified, http config interface disabled")
-		return
+		log.Printf("HTTP timing reports: listening on %v", options.HTTPPort)
+	} else {
+		http.HandleFunc("/save", basicAuth(saveHandler))
+		http.HandleFunc("/", basicAuth(indexHandler))
+		http.HandleFunc("/export", func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Add("Content-Disposition", "attachment;filename=clock.ini")
+			http.ServeFile(res, req, options.configFile)
+		})
+		http.HandleFunc("/import", basicAuth(importHandler))
+		http.HandleFunc("/rundown", basicAuth(rundownHandler))
+		http.HandleFunc("/undo", basicAuth(undoHandler))
+		log.Printf("HTTP config: listening on %v", options.HTTPPort)
 	}
 
-	http.HandleFunc("/save", basicAuth(saveHandler))
-	http.HandleFunc("/", basicAuth(indexHandler))
-	http.HandleFunc("/export", func(res http.ResponseWriter, req *http.Request) {
-		res.Header().Add("Content-Disposition", "attachment;filename=clock.ini")
-		http.ServeFile(res, req, options.configFile)
-	})
-	http.HandleFunc("/import", basicAuth(importHandler))
-
-	log.Printf("HTTP config: listening on %v", options.HTTPPort)
 	log.Fatal(http.ListenAndServe(options.HTTPPort, nil))
 }
 
@@ -118,6 +148,158 @@ func importHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// rundownHandler loads an uploaded rundown into the running clock and saves it to the rundown file
+func rundownHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseMultipartForm(10 << 20)
+	file, handler, err := r.FormFile("rundown")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	log.Printf("Uploaded rundown: %s (%d bytes)", handler.Filename, handler.Size)
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	segments, errors := parseRundown(data)
+	engine := runningEngine()
+	if engine == nil {
+		errors += "<li>Rundown: the clock engine is not running</li>"
+	}
+	if errors != "" {
+		tmpl, err := htmlTemplate.New("config.html").Parse(configHTML)
+		if err != nil {
+			panic(err)
+		}
+		page := options
+		page.Errors = htmlTemplate.HTML(fmt.Sprintf("<ul>%s</ul>", errors))
+		err = tmpl.Execute(w, page)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	engine.LoadRundown(segments)
+	if options.Rundown != "" {
+		log.Printf("Writing rundown file %s", options.Rundown)
+		if err := os.WriteFile(options.Rundown, data, 0644); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	tmpl, err := htmlTemplate.New("rundown.html").Parse(rundownHTML)
+	if err != nil {
+		panic(err)
+	}
+	err = tmpl.Execute(w, len(segments))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// undoHandler reverts the last operation on a timer and returns to the configuration page
+func undoHandler(w http.ResponseWriter, r *http.Request) {
+	engine := runningEngine()
+	if engine == nil {
+		http.Error(w, "the clock engine is not running", http.StatusServiceUnavailable)
+		return
+	}
+	counter, err := strconv.Atoi(r.FormValue("counter"))
+	if err != nil {
+		http.Error(w, "invalid timer number", http.StatusBadRequest)
+		return
+	}
+	if err := engine.UndoCounter(counter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// timingHandler serves the timing log and the segment report as CSV or JSON downloads
+func timingHandler(w http.ResponseWriter, r *http.Request) {
+	engine := runningEngine()
+	if engine == nil {
+		http.Error(w, "the clock engine is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/timing/")
+	var header []string
+	var rows [][]string
+	var data interface{}
+
+	switch strings.TrimSuffix(strings.TrimSuffix(name, ".csv"), ".json") {
+	case "log":
+		events := engine.TimingLog()
+		data = events
+		header = []string{"time", "event", "counter", "segment", "title", "countdown", "seconds", "duration", "delta", "text"}
+		for _, e := range events {
+			rows = append(rows, []string{
+				e.Time.Format(time.RFC3339),
+				e.Type,
+				strconv.Itoa(e.Counter),
+				strconv.Itoa(e.Segment),
+				e.Title,
+				strconv.FormatBool(e.Countdown),
+				strconv.Itoa(e.Seconds),
+				strconv.Itoa(e.Duration),
+				strconv.Itoa(e.Delta),
+				e.Text,
+			})
+		}
+	case "segments":
+		report := engine.SegmentReport()
+		data = report
+		header = []string{"segment", "title", "counter", "start", "end", "planned", "actual", "paused", "overrun", "expired"}
+		for _, s := range report {
+			end := ""
+			if !s.Running {
+				end = s.End.Format(time.RFC3339)
+			}
+			rows = append(rows, []string{
+				strconv.Itoa(s.Segment),
+				s.Title,
+				strconv.Itoa(s.Counter),
+				s.Start.Format(time.RFC3339),
+				end,
+				strconv.Itoa(s.Planned),
+				strconv.Itoa(s.Actual),
+				strconv.Itoa(s.Paused),
+				strconv.Itoa(s.Overrun),
+				strconv.FormatBool(s.Expired),
+			})
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Add("Content-Disposition", "attachment;filename="+name)
+	if strings.HasSuffix(name, ".json") {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(data); err != nil {
+			log.Printf("Error writing %s: %v", name, err)
+		}
+	} else if strings.HasSuffix(name, ".csv") {
+		w.Header().Set("Content-Type", "text/csv")
+		out := csv.NewWriter(w)
+		out.Write(header)
+		out.WriteAll(rows)
+		if err := out.Error(); err != nil {
+			log.Printf("Error writing %s: %v", name, err)
+		}
+	} else {
+		http.NotFound(w, r)
+	}
+}
+
 // TODO: validation
 func saveHandler(w http.ResponseWriter, r *http.Request) {
 	var newOptions clockOptions
@@ -125,10 +307,6 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	newOptions.EngineOptions = &clock.EngineOptions{}
-	newOptions.EngineOptions.Source1 = &clock.SourceOptions{}
-	newOptions.EngineOptions.Source2 = &clock.SourceOptions{}
-	newOptions.EngineOptions.Source3 = &clock.SourceOptions{}
-	newOptions.EngineOptions.Source4 = &clock.SourceOptions{}
 
 	// Booleans, no validation on them
 	newOptions.Debug = r.FormValue("Debug") != ""
@@ -141,26 +319,6 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	newOptions.EngineOptions.LTCFollow = r.FormValue("LTCFollow") != ""
 	newOptions.EngineOptions.Format12h = r.FormValue("Format12h") != ""
 
-	newOptions.EngineOptions.Source1.LTC = r.FormValue("source1-ltc") != ""
-	newOptions.EngineOptions.Source1.Timer = r.FormValue("source1-timer") != ""
-	newOptions.EngineOptions.Source1.Tod = r.FormValue("source1-tod") != ""
-	newOptions.EngineOptions.Source1.Hidden = r.FormValue("source1-hidden") != ""
-
-	newOptions.EngineOptions.Source2.LTC = r.FormValue("source2-ltc") != ""
-	newOptions.EngineOptions.Source2.Timer = r.FormValue("source2-timer") != ""
-	newOptions.EngineOptions.Source2.Tod = r.FormValue("source2-tod") != ""
-	newOptions.EngineOptions.Source2.Hidden = r.FormValue("source2-hidden") != ""
-
-	newOptions.EngineOptions.Source3.LTC = r.FormValue("source3-ltc") != ""
-	newOptions.EngineOptions.Source3.Timer = r.FormValue("source3-timer") != ""
-	newOptions.EngineOptions.Source3.Tod = r.FormValue("source3-tod") != ""
-	newOptions.EngineOptions.Source3.Hidden = r.FormValue("source3-hidden") != ""
-
-	newOptions.EngineOptions.Source4.LTC = r.FormValue("source4-ltc") != ""
-	newOptions.EngineOptions.Source4.Timer = r.FormValue("source4-timer") != ""
-	newOptions.EngineOptions.Source4.Tod = r.FormValue("source4-tod") != ""
-	newOptions.EngineOptions.Source4.Hidden = r.FormValue("source4-hidden") != ""
-
 	newOptions.DrawBoxes = r.FormValue("DrawBoxes") != ""
 
 	newOptions.EngineOptions.AutoSignals = r.FormValue("auto-signals") != ""
@@ -173,10 +331,6 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	// Strings, will not be validated
 	newOptions.HTTPUser = r.FormValue("HTTPUser")
 	newOptions.HTTPPassword = r.FormValue("HTTPPassword")
-	newOptions.EngineOptions.Source1.Text = r.FormValue("source1-text")
-	newOptions.EngineOptions.Source2.Text = r.FormValue("source2-text")
-	newOptions.EngineOptions.Source3.Text = r.FormValue("source3-text")
-	newOptions.EngineOptions.Source4.Text = r.FormValue("source4-text")
 
 	// Clock face type
 	newOptions.Face = r.FormValue("Face")
@@ -219,6 +373,17 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	// Missing BG is totally OK
 	newOptions.BackgroundPath = r.FormValue("BackgroundPath")
 	// Missing BG path is totally OK
+	newOptions.EngineOptions.StateFile = r.FormValue("state-file")
+	// Missing state file is created on first save
+	newOptions.Rundown = r.FormValue("rundown")
+	if newOptions.Rundown != "" {
+		// Missing rundown file is written on the first upload
+		errors += validateDir(newOptions.Rundown, "Rundown file")
+	}
+	newOptions.EngineOptions.HardOut = strings.TrimSpace(r.FormValue("hard-out"))
+	if err := clock.ValidateHardOut(newOptions.EngineOptions.HardOut); err != nil {
+		errors += fmt.Sprintf("<li>Show hard out: %v</li>", err)
+	}
 	newOptions.Font = r.FormValue("Font")
 	errors += validateFile(newOptions.Font, "Font for round clocks")
 
@@ -230,16 +395,6 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	newOptions.HTTPPort = r.FormValue("HTTPPort")
 	errors += validateAddr(newOptions.HTTPPort, "HTTP config interface address")
 
-	// Timezones
-	newOptions.EngineOptions.Source1.TimeZone = r.FormValue("source1-timezone")
-	errors += validateTZ(newOptions.EngineOptions.Source1.TimeZone, "Source 1 timezone")
-	newOptions.EngineOptions.Source2.TimeZone = r.FormValue("source2-timezone")
-	errors += validateTZ(newOptions.EngineOptions.Source2.TimeZone, "Source 2 timezone")
-	newOptions.EngineOptions.Source3.TimeZone = r.FormValue("source3-timezone")
-	errors += validateTZ(newOptions.EngineOptions.Source3.TimeZone, "Source 3 timezone")
-	newOptions.EngineOptions.Source4.TimeZone = r.FormValue("source4-timezone")
-	errors += validateTZ(newOptions.EngineOptions.Source4.TimeZone, "Source 4 timezone")
-
 	// Regexp
 	newOptions.EngineOptions.Ignore = r.FormValue("millumin-ignore")
 	_, err = regexp.Compile("(?i)" + newOptions.EngineOptions.Ignore)
@@ -252,24 +407,19 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	validateNumber(err, "Flash time")
 	newOptions.EngineOptions.Timeout, err = strconv.Atoi(r.FormValue("Timeout"))
 	validateNumber(err, "Tally message timeout")
-	newOptions.EngineOptions.Source1.Counter, err = strconv.Atoi(r.FormValue("source1-counter"))
-	validateNumber(err, "Source 1 timer")
-	validateTimer(newOptions.EngineOptions.Source1.Counter, "Source 1 timer")
-	newOptions.EngineOptions.Source2.Counter, err = strconv.Atoi(r.FormValue("source2-counter"))
-	validateNumber(err, "Source 2 timer")
-	validateTimer(newOptions.EngineOptions.Source2.Counter, "Source 2 timer")
-	newOptions.EngineOptions.Source3.Counter, err = strconv.Atoi(r.FormValue("source3-counter"))
-	validateNumber(err, "Source 3 timer")
-	validateTimer(newOptions.EngineOptions.Source3.Counter, "Source 3 timer")
-	newOptions.EngineOptions.Source4.Counter, err = strconv.Atoi(r.FormValue("source4-counter"))
-	validateNumber(err, "Source 4 timer")
-	validateTimer(newOptions.EngineOptions.Source4.Counter, "Source 4 timer")
+	newOptions.EngineOptions.Counters, err = strconv.Atoi(r.FormValue("counters"))
+	errors += validateNumber(err, "Number of timer counters")
+	if newOptions.EngineOptions.Counters < 1 {
+		errors += fmt.Sprintf("<li>Number of timer counters: need at least one counter (%d)</li>", newOptions.EngineOptions.Counters)
+		newOptions.EngineOptions.Counters = 1
+	}
+	counters := newOptions.EngineOptions.Counters
 	newOptions.EngineOptions.Mitti, err = strconv.Atoi(r.FormValue("mitti"))
 	validateNumber(err, "Mitti destination timer")
-	validateTimer(newOptions.EngineOptions.Mitti, "Mitti destination timer")
+	errors += validateTimer(newOptions.EngineOptions.Mitti, counters, "Mitti destination timer")
 	newOptions.EngineOptions.Millumin, err = strconv.Atoi(r.FormValue("millumin"))
 	validateNumber(err, "Millumin destination timer")
-	validateTimer(newOptions.EngineOptions.Millumin, "Millumin destination timer")
+	errors += validateTimer(newOptions.EngineOptions.Millumin, counters, "Millumin destination timer")
 	newOptions.EngineOptions.ShowInfo, err = strconv.Atoi(r.FormValue("ShowInfo"))
 	validateNumber(err, "Time to show clock info on startup")
 
@@ -278,9 +428,11 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 
 	newOptions.EngineOptions.UDPTimer1, err = strconv.Atoi(r.FormValue("udp-timer-1"))
 	validateNumber(err, "UDP Timer 1")
+	errors += validateTimer(newOptions.EngineOptions.UDPTimer1, counters, "UDP Timer 1")
 
 	newOptions.EngineOptions.UDPTimer2, err = strconv.Atoi(r.FormValue("udp-timer-2"))
 	validateNumber(err, "UDP Timer 2")
+	errors += validateTimer(newOptions.EngineOptions.UDPTimer2, counters, "UDP Timer 2")
 
 	alpha, err := strconv.Atoi(r.FormValue("row1-alpha"))
 	validateNumber(err, "Row1 alpha")
@@ -312,6 +464,18 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	newOptions.EngineOptions.SignalThresholdEnd, err = strconv.Atoi(r.FormValue("signal-threshold-end"))
 	validateNumber(err, "End signal threshold")
 
+	// Per counter threshold profiles, one per line
+	for i, line := range strings.Split(r.FormValue("timer-thresholds"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := clock.ValidateThresholds(line, counters); err != nil {
+			errors += fmt.Sprintf("<li>Timer thresholds line %d: %v</li>", i+1, err)
+		}
+		newOptions.EngineOptions.Thresholds = append(newOptions.EngineOptions.Thresholds, line)
+	}
+
 	newOptions.EngineOptions.SignalHardware, err = strconv.Atoi(r.FormValue("signal-hw-group"))
 	validateNumber(err, "Signal hardware group")
 
@@ -352,14 +516,157 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	newOptions.EngineOptions.SignalColorEnd = r.FormValue("signal-color-end")
 	errors += validateColor(newOptions.EngineOptions.SignalColorStart, "Signal color: end")
 
-	newOptions.EngineOptions.Source1.OvertimeColor = r.FormValue("source1-overtime-color")
-	errors += validateColor(newOptions.EngineOptions.Source1.OvertimeColor, "Source1 overtime color")
-	newOptions.EngineOptions.Source2.OvertimeColor = r.FormValue("source2-overtime-color")
-	errors += validateColor(newOptions.EngineOptions.Source2.OvertimeColor, "Source2 overtime color")
-	newOptions.EngineOptions.Source3.OvertimeColor = r.FormValue("source3-overtime-color")
-	errors += validateColor(newOptions.EngineOptions.Source3.OvertimeColor, "Source3 overtime color")
-	newOptions.EngineOptions.Source4.OvertimeColor = r.FormValue("source4-overtime-color")
-	errors += validateColor(newOptions.EngineOptions.Source4.OvertimeColor, "Source4 overtime color")
+	// Time sources
+	newOptions.Sources, err = strconv.Atoi(r.FormValue("sources"))
+	errors += validateNumber(err, "Number of time sources")
+	if newOptions.Sources < minSources {
+		errors += fmt.Sprintf("<li>Number of time sources: the clock faces need at least %d sources (%d)</li>", minSources, newOptions.Sources)
+		newOptions.Sources = minSources
+	}
+	newOptions.EngineOptions.Sources = make([]*clock.SourceOptions, newOptions.Sources)
+	for i := range newOptions.EngineOptions.Sources {
+		source := &clock.SourceOptions{}
+		newOptions.EngineOptions.Sources[i] = source
+
+		prefix := fmt.Sprintf("source%d-", i+1)
+		title := fmt.Sprintf("Source %d", i+1)
+		if _, ok := r.Form[prefix+"timezone"]; !ok {
+			// Newly added source, not yet on the form
+			*source = defaultSource(i, counters)
+			continue
+		}
+
+		source.Text = r.FormValue(prefix + "text")
+		source.LTC = r.FormValue(prefix+"ltc") != ""
+		source.Timer = r.FormValue(prefix+"timer") != ""
+		source.Tod = r.FormValue(prefix+"tod") != ""
+		source.Hidden = r.FormValue(prefix+"hidden") != ""
+
+		source.Counter, err = strconv.Atoi(r.FormValue(prefix + "counter"))
+		errors += validateNumber(err, title+" timer")
+		errors += validateTimer(source.Counter, counters, title+" timer")
+
+		source.TimeZone = r.FormValue(prefix + "timezone")
+		errors += validateTZ(source.TimeZone, title+" timezone")
+
+		source.Inputs = strings.TrimSpace(r.FormValue(prefix + "inputs"))
+		if err := clock.ValidateInputs(source.Inputs, counters); err != nil {
+			errors += fmt.Sprintf("<li>%s inputs: %v</li>", title, err)
+		}
+
+		source.OvertimeColor = r.FormValue(prefix + "overtime-color")
+		errors += validateColor(source.OvertimeColor, title+" overtime color")
+
+		source.OvertimeCountMode = r.FormValue(prefix + "overtime-count-mode")
+		if f := source.OvertimeCountMode; (f != "") && (f != "zero") && (f != "blank") && (f != "continue") {
+			errors += fmt.Sprintf("<li>%s overtime count mode selection is invalid (%s)</li>", title, f)
+		}
+
+		source.OvertimeVisibility = r.FormValue(prefix + "overtime-visibility")
+		if f := source.OvertimeVisibility; (f != "") && (f != "blink") && (f != "none") && (f != "background") && (f != "both") {
+			errors += fmt.Sprintf("<li>%s overtime visibility selection is invalid (%s)</li>", title, f)
+		}
+
+		source.TimeFormat = r.FormValue(prefix + "time-format")
+		if f := source.TimeFormat; (f != "") && (f != "12h") && (f != "24h") {
+			errors += fmt.Sprintf("<li>%s time format selection is invalid (%s)</li>", title, f)
+		}
+
+		source.HideSeconds = r.FormValue(prefix+"hide-seconds") != ""
+
+		source.SubSeconds, err = strconv.Atoi(r.FormValue(prefix + "sub-seconds"))
+		errors += validateNumber(err, title+" sub-second threshold")
+		if source.SubSeconds < 0 {
+			errors += fmt.Sprintf("<li>%s sub-second threshold can't be negative (%d)</li>", title, source.SubSeconds)
+		}
+
+		source.Hundredths = r.FormValue(prefix+"hundredths") != ""
+		source.BackTime = r.FormValue(prefix+"back-time") != ""
+
+		source.Format = r.FormValue(prefix + "format")
+		if err := clock.ValidateFormat(source.Format); err != nil {
+			errors += fmt.Sprintf("<li>%s format: %v</li>", title, err)
+		}
+		source.OvertimeFormat = r.FormValue(prefix + "overtime-format")
+		if err := clock.ValidateFormat(source.OvertimeFormat); err != nil {
+			errors += fmt.Sprintf("<li>%s overtime format: %v</li>", title, err)
+		}
+	}
+
+	// Counter groups, one per line
+	for i, line := range strings.Split(r.FormValue("groups"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := clock.ValidateGroup(line, counters); err != nil {
+			errors += fmt.Sprintf("<li>Timer groups line %d: %v</li>", i+1, err)
+		}
+		newOptions.EngineOptions.Groups = append(newOptions.EngineOptions.Groups, line)
+	}
+
+	// Linked counters, one per line
+	for i, line := range strings.Split(r.FormValue("timer-links"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := clock.ValidateLink(line, counters); err != nil {
+			errors += fmt.Sprintf("<li>Linked timers line %d: %v</li>", i+1, err)
+		}
+		newOptions.EngineOptions.Links = append(newOptions.EngineOptions.Links, line)
+	}
+
+	// Timer presets, one per line
+	for i, line := range strings.Split(r.FormValue("presets"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := clock.ValidatePreset(line); err != nil {
+			errors += fmt.Sprintf("<li>Timer presets line %d: %v</li>", i+1, err)
+		}
+		newOptions.EngineOptions.Presets = append(newOptions.EngineOptions.Presets, line)
+	}
+
+	// Scheduled commands, one per line
+	for i, line := range strings.Split(r.FormValue("schedule"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := clock.ValidateScheduleEntry(line, newOptions.Sources); err != nil {
+			errors += fmt.Sprintf("<li>Schedule line %d: %v</li>", i+1, err)
+		}
+		newOptions.EngineOptions.Schedule = append(newOptions.EngineOptions.Schedule, line)
+	}
+
+	// Timer actions, one per line
+	var msg string
+	newOptions.EngineOptions.ExpireActions, msg = validateTimerActions(r.FormValue("timer-expire-actions"), counters, false, "Timer expire action")
+	errors += msg
+	newOptions.EngineOptions.ThresholdActions, msg = validateTimerActions(r.FormValue("timer-threshold-actions"), counters, true, "Timer threshold action")
+	errors += msg
+
+	// Counter event hooks
+	for i, line := range strings.Split(r.FormValue("webhooks"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if u, err := url.Parse(line); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errors += fmt.Sprintf("<li>Webhook line %d: not a http or https URL (%s)</li>", i+1, line)
+		}
+		newOptions.EngineOptions.Webhooks = append(newOptions.EngineOptions.Webhooks, line)
+	}
+	newOptions.EngineOptions.WebhookTimeout, err = strconv.Atoi(r.FormValue("webhook-timeout"))
+	errors += validateNumber(err, "Webhook timeout")
+	newOptions.EngineOptions.WebhookRetries, err = strconv.Atoi(r.FormValue("webhook-retries"))
+	errors += validateNumber(err, "Webhook retries")
+	newOptions.EngineOptions.EventCommand = r.FormValue("event-command")
+	if newOptions.EngineOptions.EventCommand != "" {
+		errors += validateFile(newOptions.EngineOptions.EventCommand, "Event command")
+	}
 
 	if errors != "" {
 		tmpl, err := htmlTemplate.New("config.html").Parse(configHTML)
@@ -484,6 +791,15 @@ func validateFile(filename, title string) (msg string) {
 	return
 }
 
+// validateDir checks that the directory for a file to be written exists
+func validateDir(filename, title string) (msg string) {
+	dir := filepath.Dir(filename)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		msg = fmt.Sprintf("<li>%s: directory does not exist (%s)</li>", title, dir)
+	}
+	return
+}
+
 func validateNumber(err error, title string) (msg string) {
 	if err != nil {
 		msg = fmt.Sprintf("<li>%s: error parsing number</li>", title)
@@ -491,9 +807,24 @@ func validateNumber(err error, title string) (msg string) {
 	return
 }
 
-func validateTimer(timer int, title string) (msg string) {
-	if timer < 0 || timer > 9 {
-		msg = fmt.Sprintf("<li>%s: timer number not in range 0-9 (%d)</li>", title, timer)
+func validateTimer(timer, counters int, title string) (msg string) {
+	if timer < 0 || timer >= counters {
+		msg = fmt.Sprintf("<li>%s: timer number not in range 0-%d (%d)</li>", title, counters-1, timer)
+	}
+	return
+}
+
+// validateTimerActions splits and validates timer actions given one per line
+func validateTimerActions(text string, counters int, threshold bool, title string) (actions []string, msg string) {
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := clock.ValidateTimerAction(line, counters, threshold); err != nil {
+			msg += fmt.Sprintf("<li>%s line %d: %v</li>", title, i+1, err)
+		}
+		actions = append(actions, line)
 	}
 	return
 }
@@ -27,6 +27,9 @@ var backgroundNumber int
 
 const updateTime = time.Second / 30
 
+// minSources is the number of display sources used by the clock faces
+const minSources = 4
+
 func main() {
 	var err error
 	var info string
@@ -78,6 +81,20 @@ func main() {
 	}
 	engine.SetTitleColors(toRGBA(colors.label), toRGBA(colors.labelBG))
 
+	if options.Rundown != "" && !fileExists(options.Rundown) {
+		log.Printf("Rundown file %s does not exist yet, it is written on the first upload", options.Rundown)
+	} else if options.Rundown != "" {
+		if segments, errors := loadRundown(options.Rundown); errors != "" {
+			log.Printf("Error loading rundown %s:%s", options.Rundown, strings.NewReplacer("<li>", "\n  ", "</li>", "").Replace(errors))
+		} else {
+			engine.LoadRundown(segments)
+		}
+	}
+	if options.countdown {
+		startCountdown(engine)
+	}
+	setRunningEngine(engine)
+
 	loadBackground(options.Background)
 
 	log.Printf("Entering main loop\n")
@@ -144,7 +161,7 @@ func main() {
 				} else if options.Face == "288x144" {
 					drawSmallTextClock(state)
 				} else if options.countdown {
-					drawCountdown()
+					drawCountdown(state)
 				} else {
 					drawRoundClocks(state)
 				}
@@ -250,6 +267,8 @@ func parseOptions() {
 		return ini.ParseFile(s)
 	}
 
+	addSourceGroups(countSources())
+
 	if _, err := parser.Parse(); err != nil {
 		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
 			os.Exit(0)
@@ -271,6 +290,12 @@ func parseOptions() {
 		}
 	}
 
+	options.Sources = len(options.EngineOptions.Sources)
+	if options.EngineOptions.Counters < 1 {
+		// The default sources are spread over the counters
+		log.Fatalf("Invalid --counters=%d: at least one timer counter is needed", options.EngineOptions.Counters)
+	}
+
 	switch options.Face {
 	case "round":
 	case "dual-round":
@@ -306,6 +331,46 @@ func parseOptions() {
 	}
 }
 
+// countSources reads the number of display sources from the command line and the
+// config file, so that the option groups for them exist before the full parse
+func countSources() int {
+	var count struct {
+		Config  func(s string) error `short:"C" long:"config"`
+		Sources int                  `long:"sources" default:"4"`
+	}
+	configured := false
+	p := flags.NewParser(&count, flags.IgnoreUnknown)
+	count.Config = func(s string) error {
+		configured = true
+		return flags.NewIniParser(p).ParseFile(s)
+	}
+
+	// Errors are reported by the full parse
+	p.Parse()
+
+	if runtime.GOOS == "windows" && !configured && fileExists("clock.ini") {
+		flags.NewIniParser(p).ParseFile("clock.ini")
+	}
+	return count.Sources
+}
+
+// addSourceGroups creates the source1, source2, ... option groups for the display sources
+func addSourceGroups(count int) {
+	if count < minSources {
+		log.Printf("The clock faces need at least %d display sources, using %d", minSources, minSources)
+		count = minSources
+	}
+
+	group := parser.Group.Find("Application Options")
+	for i := 1; i <= count; i++ {
+		s := &clock.SourceOptions{}
+		g, err := group.AddGroup(fmt.Sprintf("Clock display source %d", i), "", s)
+		check(err)
+		g.Namespace = fmt.Sprintf("source%d", i)
+		options.EngineOptions.Sources = append(options.EngineOptions.Sources, s)
+	}
+}
+
 // dumpConfig dumps the clock configuration ini file to stdout
 func dumpConfig() {
 	tmpl, err := template.New("config.ini").Parse(configTemplate)
@@ -331,38 +396,18 @@ func createRings() {
 }
 
 func defaultSourceConfig() {
-	options.EngineOptions.Source1 = &clock.SourceOptions{
-		Text:          "",
-		LTC:           true,
-		Timer:         true,
-		Counter:       1,
-		Tod:           true,
-		TimeZone:      "Europe/Helsinki",
-		OvertimeColor: "#FF0000",
-	}
-	options.EngineOptions.Source2 = &clock.SourceOptions{
-		Text:          "",
-		LTC:           true,
-		Timer:         true,
-		Counter:       2,
-		Tod:           true,
-		TimeZone:      "Europe/Helsinki",
-		OvertimeColor: "#FF0000",
+	for i, s := range options.EngineOptions.Sources {
+		*s = defaultSource(i, options.EngineOptions.Counters)
 	}
-	options.EngineOptions.Source3 = &clock.SourceOptions{
-		Text:          "",
-		LTC:           true,
-		Timer:         true,
-		Counter:       3,
-		Tod:           true,
-		TimeZone:      "Europe/Helsinki",
-		OvertimeColor: "#FF0000",
-	}
-	options.EngineOptions.Source4 = &clock.SourceOptions{
+}
+
+// defaultSource returns the default configuration for the i:th display source
+func defaultSource(i, counters int) clock.SourceOptions {
+	return clock.SourceOptions{
 		Text:          "",
 		LTC:           true,
 		Timer:         true,
-		Counter:       4,
+		Counter:       (i + 1) % counters,
 		Tod:           true,
 		TimeZone:      "Europe/Helsinki",
 		OvertimeColor: "#FF0000",
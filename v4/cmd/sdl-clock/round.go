@@ -64,14 +64,10 @@ func drawRoundClocks(state *clock.State) {
 		hours := ""
 		minutes := ""
 		seconds := ""
+		days := ""
 		leds := 0
-
-		// Normalize timers over 100 hours
-		if mainClock.Hours > 99 {
-			mainClock.Hours = 99
-			mainClock.Minutes = 59
-			mainClock.Seconds = 59
-		}
+		smooth := false // Sub-second timers animate the ring within each second
+		ring := 0.0
 
 		if mainClock.Text != "" {
 			if mainClock.Mode == clock.LTC {
@@ -86,9 +82,21 @@ func drawRoundClocks(state *clock.State) {
 				}
 				colors.tally = colors.text
 
+			} else if mainClock.Mode == clock.Date && !mainClock.Hidden {
+				// Date as DD MM YY
+				hours = fmt.Sprintf("%02d", mainClock.Hours)
+				minutes = fmt.Sprintf("%02d", mainClock.Minutes)
+				seconds = fmt.Sprintf("%02d", mainClock.Seconds)
+
+			} else if mainClock.Mode == clock.Label && !mainClock.Hidden {
+				hours, minutes, seconds = splitLabel(mainClock.Text)
+
 			} else if !mainClock.Hidden {
 				// Non-LTC clocks
-				hours = fmt.Sprintf("%02d", mainClock.Hours)
+				hours = fmt.Sprintf("%02d", mainClock.Hours-mainClock.Days*24)
+				if mainClock.Days != 0 {
+					days = fmt.Sprintf("%3dd", mainClock.Days)
+				}
 
 				minutes = fmt.Sprintf("%02d", mainClock.Minutes)
 				seconds = fmt.Sprintf("%02d", mainClock.Seconds)
@@ -100,16 +108,16 @@ func drawRoundClocks(state *clock.State) {
 
 				// Shift counters with zero hours up on fields
 				if mainClock.Mode != clock.Normal &&
-					hours == "00" {
+					hours == "00" && days == "" {
 					hours = minutes
 					minutes = seconds
 					seconds = ""
 				}
 
-				// UDPTime overtime icon
-				if mainClock.Icon == "+" {
+				// UDPTime overtime icon and show over/under sign
+				if mainClock.Icon == "+" || (mainClock.Mode == clock.OverUnder && mainClock.Icon == "-") {
 					tmp := []rune(hours)
-					tmp[0] = '+'
+					tmp[0] = []rune(mainClock.Icon)[0]
 					hours = string(tmp)
 				}
 
@@ -134,6 +142,11 @@ func drawRoundClocks(state *clock.State) {
 					}
 					leds, _ = strconv.Atoi(minutes)
 				}
+
+				if mainClock.SubSeconds {
+					smooth = true
+					ring = float64(mainClock.Seconds) + mainClock.Fraction
+				}
 			}
 		}
 
@@ -153,6 +166,11 @@ func drawRoundClocks(state *clock.State) {
 				}
 			}
 		}
+		if tally == "" && days != "" {
+			// Days of long timers in the tally space
+			tally = days
+			colors.tally = colors.text
+		}
 		hourBitmap := font.TextBitmap(hours)
 		minuteBitmap := font.TextBitmap(minutes)
 		secondBitmap := font.TextBitmap(seconds)
@@ -165,7 +183,7 @@ func drawRoundClocks(state *clock.State) {
 		clearCanvas()
 
 		// Dots between hours and minutes
-		haveDisplay := (hours != "") && (minutes != "")
+		haveDisplay := (hours != "") && (minutes != "") && (mainClock.Mode != clock.Label)
 		if haveDisplay && (!mainClock.Paused || state.Flash) && (mainClock.Mode != clock.Off) {
 			drawDots(14, 15, colors.text)
 		}
@@ -177,7 +195,11 @@ func drawRoundClocks(state *clock.State) {
 		drawBitmask(tallyBitmap, colors.tally, 0, 2)
 
 		drawStaticCircles()
-		drawSecondCircles(leds)
+		if smooth {
+			drawSmoothSecondCircles(ring)
+		} else {
+			drawSecondCircles(leds)
+		}
 		renderSignal(i, mainClock.SignalColor)
 		copyIntoRect(textClock.r[i].signalTex, sdl.Rect{X: 25, Y: 905, H: 150, W: 150})
 	}
@@ -274,3 +296,9 @@ func composeRoundClocks(state *clock.State) {
 		check(err)
 	}
 }
+
+// splitLabel splits a fixed text into the two character hour, minute and second fields
+func splitLabel(text string) (hours, minutes, seconds string) {
+	r := []rune(fmt.Sprintf("%-6.6s", text))
+	return string(r[0:2]), string(r[2:4]), string(r[4:6])
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/stanchan/clock-8001/v4/clock"
+	"image/color"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Rundown import from CSV and JSON files
+ */
+
+// rundownRow is a single unvalidated rundown entry
+type rundownRow struct {
+	line       int    // Row number in the file for error messages
+	Title      string `json:"title"`
+	Duration   string `json:"duration"`   // [[HH:]MM:]SS
+	Start      string `json:"start"`      // Hard start time of day, HH:MM[:SS]
+	Counter    *int   `json:"counter"`    // Counter number, defaults to the source 1 counter
+	Color      string `json:"color"`      // Text color, #RGB or #RRGGBB
+	Background string `json:"background"` // Background color, #RGB or #RRGGBB
+	Auto       bool   `json:"auto"`       // Advance to the next segment on expiry
+}
+
+var rundownTimeRegexp = regexp.MustCompile(`^([0-1]?[0-9]|2[0-3]):([0-5][0-9])(:([0-5][0-9]))?$`)
+
+// loadRundown reads and validates a rundown file
+func loadRundown(filename string) ([]clock.Segment, string) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Sprintf("<li>Rundown: %v</li>", err)
+	}
+	return parseRundown(data)
+}
+
+// parseRundown parses a CSV or JSON rundown. The returned error string lists
+// all problems found as html list items, the segments are only valid if it is empty.
+func parseRundown(data []byte) ([]clock.Segment, string) {
+	var rows []rundownRow
+	var errors string
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		rows, errors = readRundownJSON(data)
+	} else {
+		rows, errors = readRundownCSV(data)
+	}
+	if len(rows) == 0 {
+		if errors == "" {
+			errors = "<li>Rundown: no segments found</li>"
+		}
+		return nil, errors
+	}
+
+	segments := make([]clock.Segment, len(rows))
+	for i, row := range rows {
+		var next *rundownRow
+		if i+1 < len(rows) {
+			next = &rows[i+1]
+		}
+		var msg string
+		segments[i], msg = validateRundownRow(row, next)
+		errors += msg
+	}
+	return segments, errors
+}
+
+func readRundownJSON(data []byte) ([]rundownRow, string) {
+	var rows []rundownRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Sprintf("<li>Rundown: error parsing JSON: %v</li>", err)
+	}
+	for i := range rows {
+		rows[i].line = i + 1
+	}
+	return rows, ""
+}
+
+// readRundownCSV reads a CSV rundown with a header row naming the columns
+func readRundownCSV(data []byte) ([]rundownRow, string) {
+	var rows []rundownRow
+	var errors string
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Sprintf("<li>Rundown: error reading CSV header: %v</li>", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, "<li>Rundown: missing title column</li>"
+	}
+
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Sprintf("<li>Rundown: error reading CSV: %v</li>", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			// Skip empty rows
+			continue
+		}
+
+		row := rundownRow{
+			line:       line,
+			Title:      field("title"),
+			Duration:   field("duration"),
+			Start:      field("start"),
+			Color:      field("color"),
+			Background: field("background"),
+		}
+		if s := field("counter"); s != "" {
+			counter, err := strconv.Atoi(s)
+			if err != nil {
+				errors += fmt.Sprintf("<li>Rundown row %d counter: error parsing number (%s)</li>", line, s)
+			} else {
+				row.Counter = &counter
+			}
+		}
+		switch strings.ToLower(field("auto")) {
+		case "", "0", "no", "false":
+		case "1", "x", "yes", "true":
+			row.Auto = true
+		default:
+			errors += fmt.Sprintf("<li>Rundown row %d auto: expected yes or no (%s)</li>", line, field("auto"))
+		}
+		rows = append(rows, row)
+	}
+	return rows, errors
+}
+
+// validateRundownRow converts a rundown row to a segment. A row without a
+// duration counts down to the hard start time of the following row.
+func validateRundownRow(row rundownRow, next *rundownRow) (segment clock.Segment, msg string) {
+	title := fmt.Sprintf("Rundown row %d", row.line)
+
+	segment.Title = row.Title
+	segment.AutoAdvance = row.Auto
+	if row.Title == "" {
+		msg += fmt.Sprintf("<li>%s: missing segment title</li>", title)
+	}
+
+	if row.Start != "" && !rundownTimeRegexp.MatchString(row.Start) {
+		msg += fmt.Sprintf("<li>%s: start time not in HH:MM or HH:MM:SS format (%s)</li>", title, row.Start)
+	}
+
+	if row.Duration != "" {
+		d, err := clock.ParseDuration(row.Duration)
+		if err != nil {
+			msg += fmt.Sprintf("<li>%s: duration not in [[HH:]MM:]SS format (%s)</li>", title, row.Duration)
+		}
+		segment.Duration = d
+	} else if next != nil && rundownTimeRegexp.MatchString(next.Start) {
+		m := rundownTimeRegexp.FindStringSubmatch(next.Start)
+		h, _ := strconv.Atoi(m[1])
+		seconds := m[4]
+		if seconds == "" {
+			seconds = "00"
+		}
+		segment.Target = fmt.Sprintf("%02d:%s:%s", h, m[2], seconds)
+	} else {
+		msg += fmt.Sprintf("<li>%s: needs a duration or a hard start time on the following row</li>", title)
+	}
+
+	segment.Counter = options.EngineOptions.Sources[0].Counter
+	if row.Counter != nil {
+		segment.Counter = *row.Counter
+	}
+	msg += validateTimer(segment.Counter, options.EngineOptions.Counters, title+" counter")
+
+	if row.Color != "" || row.Background != "" {
+		msg += validateColor(row.Color, title+" color")
+		msg += validateColor(row.Background, title+" background")
+		text, errText := parseColor(row.Color)
+		bg, errBG := parseColor(row.Background)
+		if errText == nil && errBG == nil {
+			segment.Colors = []color.RGBA{toRGBA(text), toRGBA(bg)}
+		}
+	}
+	return
+}
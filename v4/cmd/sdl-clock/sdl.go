@@ -281,6 +281,37 @@ func drawSecondCircles(seconds int) {
 	}
 }
 
+// drawSmoothSecondCircles draws the second marker circles up to a fractional second,
+// fading in the last circle by the fraction
+func drawSmoothSecondCircles(seconds float64) {
+	if seconds > 60 {
+		seconds = 60
+	} else if seconds < 0 {
+		seconds = 0
+	}
+	whole := int(seconds)
+	for i := 0; i < whole && i < 60; i++ {
+		dest := sdl.Rect{X: secCircles[i].X - 20, Y: secCircles[i].Y - 20, W: 40, H: 40}
+		if options.small {
+			dest = sdl.Rect{X: secCircles[i].X - 3, Y: secCircles[i].Y - 3, W: 5, H: 5}
+		}
+		err := renderer.Copy(secTexture, &textureSource, &dest)
+		check(err)
+	}
+	if whole < 60 {
+		dest := sdl.Rect{X: secCircles[whole].X - 20, Y: secCircles[whole].Y - 20, W: 40, H: 40}
+		if options.small {
+			dest = sdl.Rect{X: secCircles[whole].X - 3, Y: secCircles[whole].Y - 3, W: 5, H: 5}
+		}
+		err := secTexture.SetAlphaMod(uint8((seconds - float64(whole)) * 255))
+		check(err)
+		err = renderer.Copy(secTexture, &textureSource, &dest)
+		check(err)
+		err = secTexture.SetAlphaMod(255)
+		check(err)
+	}
+}
+
 // drawStaticCircles draws the 12 static "hour" marker circles
 func drawStaticCircles() {
 	// Draw static indicator circles
@@ -93,6 +93,9 @@ func drawSmallTextClock(state *clock.State) {
 				minutes = fmt.Sprintf("%02d", clk.Seconds)
 				seconds = fmt.Sprintf("%02d", clk.Frames)
 
+			} else if clk.Mode == clock.Label {
+				hours, minutes, seconds = splitLabel(clk.Text)
+
 			} else {
 				// Non-LTC clocks
 				hours = fmt.Sprintf("%02d", clk.Hours)
@@ -172,7 +175,7 @@ func drawSmallTextClock(state *clock.State) {
 		}
 
 		// Dots between hours, minutes and seconds (+ franes for LTC)
-		if (!clk.Paused || state.Flash) && (clk.Mode != clock.Off) {
+		if (!clk.Paused || state.Flash) && (clk.Mode != clock.Off) && (clk.Mode != clock.Label) {
 			dots := 2
 			firstY := smallTextClockY + 1
 			if clk.Mode == clock.LTC {
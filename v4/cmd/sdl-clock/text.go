@@ -11,6 +11,7 @@ import (
 	"log"
 	"regexp"
 	"strconv"
+	"time"
 )
 
 type outputLine struct {
@@ -20,6 +21,10 @@ type outputLine struct {
 	iconTex       *sdl.Texture
 	textTex       *sdl.Texture
 	labelTex      *sdl.Texture
+	lap           [2]string
+	lapTex        [2]*sdl.Texture
+	endTime       string
+	endTimeTex    *sdl.Texture
 	signalTex     *sdl.Texture
 	timeFragments [10]*sdl.Texture
 	fragmentRect  sdl.Rect
@@ -89,6 +94,9 @@ func drawTextClock(state *clock.State) {
 		}
 
 		text := clk.Text
+		if clk.Days != 0 && !clk.Formatted {
+			text = fmt.Sprintf("%dd %02d:%02d:%02d", clk.Days, clk.Hours-clk.Days*24, clk.Minutes, clk.Seconds)
+		}
 		if clk.Expired && clk.Mode == clock.Countdown {
 			if !state.Flash {
 				text = " "
@@ -97,20 +105,37 @@ func drawTextClock(state *clock.State) {
 			}
 		}
 
-		if clk.Expired && clk.Mode == clock.Countup {
+		if clk.Expired && clk.Mode == clock.Countup && !clk.Formatted {
 			text = "00:00:00"
 		}
 
+		if clk.SubSeconds && !clk.Formatted {
+			if clk.Hundredths {
+				text = fmt.Sprintf("%02d:%02d.%02d", clk.Minutes, clk.Seconds, int(clk.Fraction*100))
+			} else {
+				text = fmt.Sprintf("%02d:%02d.%d", clk.Minutes, clk.Seconds, int(clk.Fraction*10))
+			}
+		}
+
 		renderNumbers(i, text, toSDLColor(clk.TextColor))
 		titleColor := toSDLColor(state.TitleColor)
 		if colors.label != titleColor {
 			for row := range textClock.r {
 				textClock.r[row].label = ""
+				textClock.r[row].lap = [2]string{}
+				textClock.r[row].endTime = ""
 			}
 		}
 		renderLabel(i, fmt.Sprintf("%.10s", clk.Label), titleColor)
 		renderIcon(i, clk.Icon, colors.row[i])
 		renderSignal(i, clk.SignalColor)
+		if clk.Lap > 0 {
+			renderSecondary(&textClock.r[i].lap[0], &textClock.r[i].lapTex[0], fmt.Sprintf("Lap %d %s", clk.Lap, lapTime(clk.LapTime)), titleColor)
+			renderSecondary(&textClock.r[i].lap[1], &textClock.r[i].lapTex[1], fmt.Sprintf("Last %s", lapTime(clk.LastLap)), titleColor)
+		}
+		if clk.EndTime != "" {
+			renderSecondary(&textClock.r[i].endTime, &textClock.r[i].endTimeTex, "Ends "+clk.EndTime, titleColor)
+		}
 	}
 
 	// Clear output and setup background
@@ -144,6 +169,14 @@ func drawSingleLineClock(state *clock.State) {
 
 	copyIntoRect(textClock.r[0].labelTex, labelR)
 	copyIntoRect(textClock.r[0].signalTex, signalR)
+	if state.Clocks[0].Lap > 0 {
+		// Current and previous stopwatch lap between the label and signal
+		copyIntoRect(textClock.r[0].lapTex[0], sdl.Rect{X: 950, Y: 115, W: 775, H: 70})
+		copyIntoRect(textClock.r[0].lapTex[1], sdl.Rect{X: 950, Y: 195, W: 775, H: 70})
+	} else if state.Clocks[0].EndTime != "" {
+		// Back-timed end of the countdown
+		copyIntoRect(textClock.r[0].endTimeTex, sdl.Rect{X: 950, Y: 115, W: 775, H: 70})
+	}
 	if state.Clocks[0].Mode != clock.LTC {
 		// Clock time
 
@@ -194,6 +227,14 @@ func draw3TextClocks(state *clock.State) {
 
 		copyIntoRect(textClock.r[i].signalTex, signalR)
 		copyIntoRect(textClock.r[i].labelTex, labelR)
+		if state.Clocks[i].Lap > 0 {
+			// Current and previous stopwatch lap left of the signal
+			copyIntoRect(textClock.r[i].lapTex[0], sdl.Rect{X: x, Y: y + 125, W: 330, H: 70})
+			copyIntoRect(textClock.r[i].lapTex[1], sdl.Rect{X: x, Y: y + 205, W: 330, H: 70})
+		} else if state.Clocks[i].EndTime != "" {
+			// Back-timed end of the countdown
+			copyIntoRect(textClock.r[i].endTimeTex, sdl.Rect{X: x, Y: y + 125, W: 330, H: 70})
+		}
 		if state.Clocks[i].Mode != clock.LTC {
 			// Clock time
 
@@ -415,6 +456,26 @@ func renderLabel(i int, label string, textColor sdl.Color) {
 	}
 }
 
+// renderSecondary renders a secondary label, like lap or end times, if the text has changed
+func renderSecondary(current *string, tex **sdl.Texture, text string, textColor sdl.Color) {
+	if *current != text {
+		*current = text
+		if *tex != nil {
+			(*tex).Destroy()
+		}
+		*tex = renderText(text, textClock.labelFont, textColor)
+	}
+}
+
+// lapTime formats a lap duration as MM:SS or H:MM:SS
+func lapTime(d time.Duration) string {
+	s := int(d.Round(time.Second).Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, (s/60)%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
 func drawTally(state *clock.State) {
 	// Draw possible OSC text message
 	if state.Tally != "" {
@@ -494,6 +555,8 @@ func materialIcon(icon string) string {
 		return "\ue037"
 	case "+":
 		return "\ue145"
+	case "-":
+		return "\ue15b"
 	}
 	return ""
 }